@@ -6,15 +6,25 @@ import (
 	"os"
 
 	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Authenticate with Google Calendar",
-	Long:  "Start OAuth 2.0 flow to authenticate with Google Calendar API.",
+	Long:  "Start OAuth 2.0 flow to authenticate with Google Calendar API.\nUse --account to add another Google account, e.g. work and personal.",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
+		account, _ := cmd.Flags().GetString("account")
+		port, _ := cmd.Flags().GetInt("port")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+
+		if account != "" && !config.ValidAccountName(account) {
+			fmt.Fprintf(os.Stderr, "Invalid account name %q: use letters, digits, '-' and '_'\n", account)
+			os.Exit(1)
+		}
 
 		configDir, err := calendar.ConfigDir()
 		if err != nil {
@@ -22,24 +32,49 @@ var authCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		store, err := cfg.TokenStore.Open()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open token store: %v\n", err)
+			os.Exit(1)
+		}
+
 		fmt.Printf("Config directory: %s\n", configDir)
 		fmt.Println("Starting Google OAuth authentication...")
 
-		token, err := calendar.Authenticate(ctx)
+		token, err := calendar.Authenticate(ctx, account, calendar.AuthOptions{
+			Port:      port,
+			Timeout:   timeout,
+			NoBrowser: noBrowser,
+			Input:     os.Stdin,
+			RSVP:      cfg.RSVP,
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
 			os.Exit(1)
 		}
 
-		if err := calendar.SaveToken(token); err != nil {
+		if err := store.Save(calendar.TokenKey(account), token); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to save token: %v\n", err)
 			os.Exit(1)
 		}
 
 		fmt.Println("Authentication successful! Token saved.")
+		if account != "" {
+			fmt.Printf("Add the account to config.toml to watch it:\n\n[[accounts]]\nname = %q\n", account)
+		}
 	},
 }
 
 func init() {
+	authCmd.Flags().String("account", "", "name of an additional Google account (e.g. work, personal)")
+	authCmd.Flags().Int("port", 0, "loopback port for the OAuth redirect (default: any free port)")
+	authCmd.Flags().Bool("no-browser", false, "print the URL and paste the redirect URL back instead of opening a browser (for SSH)")
+	authCmd.Flags().Duration("timeout", calendar.DefaultAuthTimeout, "how long to wait for authentication in the browser")
 	rootCmd.AddCommand(authCmd)
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCalDAVCmd = &cobra.Command{
+	Use:   "caldav",
+	Short: "Store an app password for a CalDAV calendar",
+	Long:  "Store the username and app password for a CalDAV calendar configured in config.toml.",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		username, _ := cmd.Flags().GetString("username")
+
+		fmt.Print("App password: ")
+		password, err := readPassword()
+		fmt.Println()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read password: %v\n", err)
+			os.Exit(1)
+		}
+		if password == "" {
+			fmt.Fprintln(os.Stderr, "Password must not be empty.")
+			os.Exit(1)
+		}
+
+		_, store := loadTokenStore()
+		creds := calendar.CalDAVCredentials{Username: username, Password: password}
+		if err := calendar.SaveCalDAVCredentials(store, name, creds); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save credentials: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Credentials for %s saved to the token store\n", name)
+	},
+}
+
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		return string(b), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func init() {
+	authCalDAVCmd.Flags().String("name", "", "CalDAV calendar name as configured in config.toml")
+	authCalDAVCmd.Flags().String("username", "", "CalDAV username")
+	authCalDAVCmd.MarkFlagRequired("name")
+	authCalDAVCmd.MarkFlagRequired("username")
+	authCmd.AddCommand(authCalDAVCmd)
+}
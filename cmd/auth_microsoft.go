@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var authMicrosoftCmd = &cobra.Command{
+	Use:   "microsoft",
+	Short: "Authenticate with Microsoft 365 / Outlook",
+	Long:  "Start the OAuth 2.0 device code flow to authenticate with Microsoft Graph.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !cfg.Microsoft.Enabled() {
+			fmt.Fprintln(os.Stderr, "Set [microsoft] client_id in config.toml first.")
+			os.Exit(1)
+		}
+
+		fmt.Println("Starting Microsoft authentication...")
+
+		oauthConfig := calendar.GraphOAuthConfig(cfg.Microsoft.ClientID, cfg.Microsoft.Tenant)
+		token, err := calendar.AuthenticateGraph(ctx, oauthConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		store, err := cfg.TokenStore.Open()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open token store: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := store.Save(calendar.GraphTokenKey, token); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save token: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Authentication successful! Token saved.")
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authMicrosoftCmd)
+}
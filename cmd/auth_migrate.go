@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var authMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext tokens into the configured token store",
+	Long:  "Move token.json, token-<account>.json and graph-token.json into the token store\nselected by [token_store] in config.toml and delete the plaintext files.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		backend := cfg.TokenStore.ResolvedBackend()
+		if backend == config.BackendFile {
+			fmt.Fprintln(os.Stderr, "The token store is plaintext files; set [token_store] backend in config.toml first.")
+			os.Exit(1)
+		}
+
+		store, err := cfg.TokenStore.Open()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open token store: %v\n", err)
+			os.Exit(1)
+		}
+		legacy, err := calendar.NewFileTokenStore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		keys := []string{calendar.TokenKey(""), calendar.GraphTokenKey}
+		for _, account := range cfg.Accounts {
+			keys = append(keys, calendar.TokenKey(account.Name))
+		}
+
+		migrated := 0
+		for _, key := range keys {
+			ok, err := calendar.MigrateToken(legacy, store, key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if ok {
+				fmt.Printf("Moved %s.json to %s\n", key, backend)
+				migrated++
+			}
+		}
+
+		if migrated == 0 {
+			fmt.Println("No plaintext tokens found.")
+		}
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authMigrateCmd)
+}
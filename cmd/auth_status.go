@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/launchd"
+	"github.com/spf13/cobra"
+)
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show authenticated accounts, granted scopes and token expiry",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		cfg, store := loadTokenStore()
+
+		fmt.Printf("Token store: %s\n\n", cfg.TokenStore.ResolvedBackend())
+
+		for _, account := range googleAccounts(cfg) {
+			fmt.Printf("Google (%s): ", accountLabel(account))
+
+			if _, err := store.Load(calendar.TokenKey(account)); errors.Is(err, calendar.ErrTokenNotFound) {
+				fmt.Printf("not authenticated, run '%s'\n", authCommand(account))
+				continue
+			}
+
+			info, err := calendar.InspectToken(ctx, account, store)
+			if err != nil {
+				fmt.Printf("error: %v\n", err)
+				continue
+			}
+
+			email := info.Email
+			if email == "" {
+				email = "authenticated"
+			}
+			fmt.Println(email)
+			fmt.Printf("  Scopes:  %s\n", strings.Join(info.Scopes, ", "))
+			fmt.Printf("  Expires: %s (refreshed automatically)\n", info.Expiry.Local().Format("2006-01-02 15:04"))
+		}
+
+		if cfg.Microsoft.Enabled() {
+			fmt.Print("Microsoft: ")
+			token, err := store.Load(calendar.GraphTokenKey)
+			switch {
+			case errors.Is(err, calendar.ErrTokenNotFound):
+				fmt.Println("not authenticated, run 'ooi auth microsoft'")
+			case err != nil:
+				fmt.Printf("error: %v\n", err)
+			default:
+				fmt.Println("authenticated")
+				fmt.Printf("  Expires: %s (refreshed automatically)\n", token.Expiry.Local().Format("2006-01-02 15:04"))
+			}
+		}
+	},
+}
+
+var authRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke Google access and remove the local token",
+	Long:  "Revoke the token at Google so ooi can no longer read the calendar, then remove it locally.\nUse --all to revoke every Google account and also remove the Microsoft token.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		account, _ := cmd.Flags().GetString("account")
+		all, _ := cmd.Flags().GetBool("all")
+		cfg, store := loadTokenStore()
+
+		accounts := []string{account}
+		if all {
+			accounts = googleAccounts(cfg)
+		}
+
+		failed := false
+		for _, account := range accounts {
+			if err := revokeGoogle(ctx, store, account); err != nil {
+				fmt.Fprintf(os.Stderr, "Google (%s): %v\n", accountLabel(account), err)
+				failed = true
+			}
+		}
+		if all {
+			removeMicrosoftToken(store)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var authLogoutCmd = newLogoutCmd()
+
+// logoutCmd is 'ooi logout', a shortcut for 'ooi auth logout'.
+var logoutCmd = newLogoutCmd()
+
+func newLogoutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove local credentials without revoking them",
+		Long:  "Remove the stored token of a Google account. Use --all to remove every token and CalDAV password.\nAccess is not revoked at Google; use 'ooi auth revoke' for that.",
+		Run: func(cmd *cobra.Command, args []string) {
+			account, _ := cmd.Flags().GetString("account")
+			all, _ := cmd.Flags().GetBool("all")
+			cfg, store := loadTokenStore()
+
+			accounts := []string{account}
+			if all {
+				accounts = googleAccounts(cfg)
+			}
+
+			for _, account := range accounts {
+				if err := store.Delete(calendar.TokenKey(account)); err != nil {
+					fmt.Fprintf(os.Stderr, "Google (%s): %v\n", accountLabel(account), err)
+					os.Exit(1)
+				}
+				fmt.Printf("Google (%s): removed local token\n", accountLabel(account))
+			}
+
+			if all {
+				removeMicrosoftToken(store)
+				if err := removeCalDAVCredentials(cfg, store); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to remove CalDAV passwords: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			if launchd.IsInstalled() {
+				fmt.Println("The daemon stops fetching when its current access token expires (within an hour).")
+			}
+		},
+	}
+	cmd.Flags().String("account", "", "name of an additional Google account")
+	cmd.Flags().Bool("all", false, "remove the credentials of every account")
+	return cmd
+}
+
+func loadTokenStore() (*config.Config, calendar.TokenStore) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := cfg.TokenStore.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open token store: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg, store
+}
+
+// googleAccounts returns the default account ("") and the configured accounts.
+func googleAccounts(cfg *config.Config) []string {
+	accounts := []string{""}
+	for _, account := range cfg.Accounts {
+		accounts = append(accounts, account.Name)
+	}
+	return accounts
+}
+
+func accountLabel(account string) string {
+	if account == "" {
+		return "default"
+	}
+	return account
+}
+
+func authCommand(account string) string {
+	if account == "" {
+		return "ooi auth"
+	}
+	return "ooi auth --account " + account
+}
+
+// revokeGoogle revokes the account's token at Google and removes it from the store.
+func revokeGoogle(ctx context.Context, store calendar.TokenStore, account string) error {
+	label := accountLabel(account)
+	token, err := store.Load(calendar.TokenKey(account))
+	if errors.Is(err, calendar.ErrTokenNotFound) {
+		fmt.Printf("Google (%s): not authenticated\n", label)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err = calendar.RevokeToken(ctx, token)
+	switch {
+	case errors.Is(err, calendar.ErrTokenAlreadyRevoked):
+		fmt.Printf("Google (%s): token was already revoked\n", label)
+	case err != nil:
+		return err
+	default:
+		fmt.Printf("Google (%s): access revoked\n", label)
+	}
+
+	return store.Delete(calendar.TokenKey(account))
+}
+
+func removeMicrosoftToken(store calendar.TokenStore) {
+	if err := store.Delete(calendar.GraphTokenKey); err != nil {
+		fmt.Fprintf(os.Stderr, "Microsoft: %v\n", err)
+		return
+	}
+	fmt.Println("Microsoft: removed local token (remove ooi's access at https://myapps.microsoft.com)")
+}
+
+// removeCalDAVCredentials removes the app passwords of the configured CalDAV
+// calendars and the legacy caldav.json.
+func removeCalDAVCredentials(cfg *config.Config, store calendar.TokenStore) error {
+	for _, caldav := range cfg.CalDAV {
+		if err := calendar.DeleteCalDAVCredentials(store, caldav.Name); err != nil {
+			return err
+		}
+	}
+	path, err := calendar.CalDAVCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	authRevokeCmd.Flags().String("account", "", "name of an additional Google account")
+	authRevokeCmd.Flags().Bool("all", false, "revoke every Google account and remove the Microsoft token")
+
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authRevokeCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/filter"
+	"github.com/knwoop/ooi/internal/launchd"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings in config.toml",
+	Long: `Show and change settings in config.toml.
+Settings can be overridden with OOI_* environment variables, e.g. OOI_FETCH_INTERVAL
+for fetch_interval and OOI_QUIET_HOURS_ACTION for quiet_hours.action.`,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of config.toml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := config.Path()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(path)
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print a setting, or all settings",
+	Long:  "Print the value in effect for key, including environment variable overrides.\nWithout a key, all settings are printed.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		keys := config.Keys()
+		if len(args) == 1 {
+			keys = args
+		}
+		for _, key := range keys {
+			value, err := cfg.Get(key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if len(args) == 1 {
+				fmt.Println(value)
+				continue
+			}
+			if _, ok := os.LookupEnv(config.EnvName(key)); ok {
+				value += " (from " + config.EnvName(key) + ")"
+			}
+			fmt.Printf("%s = %s\n", key, value)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value> [<key> <value>...]",
+	Short: "Change settings in config.toml",
+	Long: `Validate and write settings to config.toml. Lists are comma separated and an
+empty value restores the default. Settings that depend on each other are set together:
+
+  ooi config set quiet_hours.start 19:00 quiet_hours.end 09:00
+
+The file is rewritten, so comments in it are not kept.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || len(args)%2 != 0 {
+			return fmt.Errorf("expected key and value pairs, got %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := config.Path()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		settings := make(map[string]string)
+		for i := 0; i < len(args); i += 2 {
+			settings[args[i]] = args[i+1]
+		}
+		if err := config.SetInFile(path, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to change config: %v\n", err)
+			os.Exit(1)
+		}
+
+		for i := 0; i < len(args); i += 2 {
+			fmt.Printf("Set %s in %s\n", args[i], path)
+			if _, ok := os.LookupEnv(config.EnvName(args[i])); ok {
+				fmt.Printf("Note: %s is set and overrides this setting.\n", config.EnvName(args[i]))
+			}
+		}
+		if launchd.IsInstalled() {
+			fmt.Println("Run 'ooi reinstall' to restart the daemon with the new setting.")
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config.toml and rules.toml",
+	Long:  "Check config.toml, including environment variable overrides, and rules.toml for\nunknown keys and invalid values.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := config.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "config.toml: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := filter.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "rules.toml: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("config.toml and rules.toml are valid.")
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -9,8 +9,9 @@ import (
 	"runtime/debug"
 	"syscall"
 
-	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
 	"github.com/knwoop/ooi/internal/daemon"
+	"github.com/knwoop/ooi/internal/filter"
 	"github.com/knwoop/ooi/internal/menubar"
 	"github.com/spf13/cobra"
 )
@@ -18,7 +19,7 @@ import (
 var rootCmd = &cobra.Command{
 	Use:     "ooi",
 	Short:   "Meeting reminder CLI tool",
-	Long:    "ooi is a macOS CLI tool that automatically opens Google Meet 1 minute before meetings.",
+	Long:    "ooi is a macOS CLI tool that automatically opens Google Meet, Zoom, Teams and Webex 1 minute before meetings.",
 	Version: getVersion(),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -35,19 +36,25 @@ var rootCmd = &cobra.Command{
 
 		log.Println("Starting ooi daemon...")
 
-		token, err := calendar.LoadToken()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Not authenticated. Run 'ooi auth' first.\n")
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 			os.Exit(1)
 		}
 
-		client, err := calendar.NewClient(ctx, token)
+		source, err := daemon.NewEventSource(ctx, cfg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to create calendar client: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to create event source: %v\n", err)
 			os.Exit(1)
 		}
 
-		scheduler := daemon.NewScheduler(client)
+		rules, err := filter.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		scheduler := daemon.NewScheduler(source, cfg, rules)
 
 		// Run scheduler in background
 		go func() {
@@ -59,7 +66,10 @@ var rootCmd = &cobra.Command{
 		}()
 
 		// Run systray on main thread (required by systray library)
-		menubar.Run(ctx, scheduler)
+		menubar.Run(ctx, scheduler, menubar.Options{
+			Location:    cfg.DisplayLocation(),
+			TitleLength: cfg.MenubarTitleLength,
+		})
 	},
 }
 
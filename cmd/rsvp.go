@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+var rsvpCmd = &cobra.Command{
+	Use:   "rsvp <event> accept|decline|tentative",
+	Short: "Respond to a meeting invitation",
+	Long: `Respond to an invitation and notify the organizer.
+<event> is "next" for the next meeting, an event ID, or part of the title.
+Requires rsvp = true in config.toml and running 'ooi auth' again to grant access.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		response, err := calendar.ParseResponse(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		if !cfg.RSVP {
+			fmt.Fprintln(os.Stderr, "RSVP is disabled. Set rsvp = true in config.toml and run 'ooi auth' again.")
+			os.Exit(1)
+		}
+
+		source, err := daemon.NewEventSource(ctx, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create event source: %v\n", err)
+			os.Exit(1)
+		}
+
+		events, err := source.GetEventsInRange(ctx, cfg.StatusLookback.Duration, cfg.Lookahead.Duration)
+		if err != nil && !partialFetch(err) {
+			fmt.Fprintf(os.Stderr, "Failed to fetch events: %v\n", err)
+			os.Exit(1)
+		}
+
+		matches := findEvents(events, args[0], time.Now())
+		switch len(matches) {
+		case 0:
+			fmt.Fprintf(os.Stderr, "No upcoming meeting matches %q.\n", args[0])
+			os.Exit(1)
+		case 1:
+		default:
+			loc := cfg.DisplayLocation()
+			fmt.Fprintf(os.Stderr, "%q matches several meetings, use the event ID:\n", args[0])
+			for _, event := range matches {
+				fmt.Fprintf(os.Stderr, "  %s  %s  %s\n", event.ID, event.StartTime.In(loc).Format("01/02 15:04"), event.Title)
+			}
+			os.Exit(1)
+		}
+
+		event := matches[0]
+		if err := calendar.RSVP(ctx, source, event, response); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to respond: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Responded %s to %s at %s.\n", response, event.Title, event.StartTime.In(cfg.DisplayLocation()).Format("01/02 15:04"))
+	},
+}
+
+// findEvents returns the events matching query: "next" for the next meeting,
+// an exact event ID, or otherwise a case-insensitive part of the title.
+func findEvents(events []calendar.Event, query string, now time.Time) []calendar.Event {
+	if query == "next" {
+		for _, event := range events {
+			if event.StartTime.After(now) {
+				return []calendar.Event{event}
+			}
+		}
+		return nil
+	}
+
+	for _, event := range events {
+		if event.ID == query {
+			return []calendar.Event{event}
+		}
+	}
+
+	var matches []calendar.Event
+	for _, event := range events {
+		if event.EndTime.After(now) && strings.Contains(strings.ToLower(event.Title), strings.ToLower(query)) {
+			matches = append(matches, event)
+		}
+	}
+	return matches
+}
+
+func init() {
+	rootCmd.AddCommand(rsvpCmd)
+}
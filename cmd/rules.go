@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/daemon"
+	"github.com/knwoop/ooi/internal/filter"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show how rules apply to upcoming meetings",
+	Long:  "Evaluate rules.toml against upcoming meetings and print whether each one alerts and why.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		rules, err := filter.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		source, err := daemon.NewEventSource(ctx, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create event source: %v\n", err)
+			os.Exit(1)
+		}
+
+		events, err := source.GetEventsInRange(ctx, 0, cfg.Lookahead.Duration)
+		if err != nil && !partialFetch(err) {
+			fmt.Fprintf(os.Stderr, "Failed to fetch events: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No upcoming meetings with conference links.")
+			return
+		}
+
+		loc := cfg.DisplayLocation()
+		for _, event := range events {
+			d := rules.Evaluate(event)
+			mark := "✓"
+			if !d.Included {
+				mark = "✗"
+			}
+			fmt.Printf("%s %s %s (%s)\n    %s\n", mark, event.StartTime.In(loc).Format("01/02 15:04"), event.Title, calendarLabel(&event), d)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+}
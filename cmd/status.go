@@ -2,50 +2,68 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/daemon"
+	"github.com/knwoop/ooi/internal/filter"
 	"github.com/spf13/cobra"
 )
 
-const (
-	statusLookBack  = 2 * time.Hour
-	statusLookAhead = 3 * time.Hour
-)
-
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show upcoming meetings",
-	Long:  "Display upcoming meetings with Google Meet links.",
+	Long:  "Display upcoming meetings with conference links.",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 
-		token, err := calendar.LoadToken()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Not authenticated. Run 'ooi auth' first.\n")
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 			os.Exit(1)
 		}
 
-		client, err := calendar.NewClient(ctx, token)
+		rules, err := filter.Load()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to create calendar client: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to load rules: %v\n", err)
 			os.Exit(1)
 		}
 
-		events, err := client.GetEventsInRange(ctx, statusLookBack, statusLookAhead)
+		source, err := daemon.NewEventSource(ctx, cfg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to fetch events: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to create event source: %v\n", err)
 			os.Exit(1)
 		}
 
+		events, err := source.GetEventsInRange(ctx, cfg.StatusLookback.Duration, cfg.StatusLookahead.Duration)
+		switch {
+		case err == nil:
+		case partialFetch(err):
+			// Calendars that failed are shown from the daemon's cache, if any
+			if cache, _ := daemon.LoadCache(cfg); cache != nil {
+				events = calendar.KeepMissingCalendars(events, statusRange(cfg, cache.Events, time.Now()))
+			}
+		default:
+			events = cachedEvents(cfg, err)
+		}
+
+		if !cfg.AlertAllDay {
+			events = calendar.ExcludeAllDay(events)
+		}
+		events = rules.Apply(events)
+
 		if len(events) == 0 {
-			fmt.Println("No upcoming meetings with Google Meet.")
+			fmt.Println("No upcoming meetings with conference links.")
 			return
 		}
 
 		now := time.Now()
+		loc := cfg.DisplayLocation()
 
 		// Find ongoing and next meetings
 		var ongoingEvent *calendar.Event
@@ -73,30 +91,131 @@ var statusCmd = &cobra.Command{
 		}
 
 		if ongoingEvent == nil && nextEvent == nil {
-			fmt.Println("No upcoming meetings with Google Meet.")
+			fmt.Println("No upcoming meetings with conference links.")
 			return
 		}
 
 		if ongoingEvent != nil {
-			printMeeting("Ongoing meeting", ongoingEvent, "[ongoing]")
+			printMeeting("Ongoing meeting", ongoingEvent, "[ongoing]", loc)
 		}
 
 		if nextEvent != nil {
 			if ongoingEvent != nil {
 				fmt.Println()
 			}
-			printMeeting("Next meeting", nextEvent, formatEventStatus(nextEvent.StartTime, now))
+			printMeeting("Next meeting", nextEvent, formatEventStatus(nextEvent.StartTime, now), loc)
 		}
 	},
 }
 
-func printMeeting(label string, event *calendar.Event, timeStatus string) {
+// partialFetch reports whether err only concerns some calendars and prints it
+// as a warning.
+func partialFetch(err error) bool {
+	var partial *calendar.PartialError
+	if !errors.As(err, &partial) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	return true
+}
+
+// cachedEvents falls back to the daemon's event cache when fetching fails.
+func cachedEvents(cfg *config.Config, fetchErr error) []calendar.Event {
+	cache, err := daemon.LoadCache(cfg)
+	if err != nil || cache == nil {
+		fmt.Fprintf(os.Stderr, "Failed to fetch events: %v\n", fetchErr)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	label := "Showing cached events"
+	if cache.Stale(now) {
+		label = "Showing STALE cached events"
+	}
+	fmt.Fprintf(os.Stderr, "%s fetched at %s (%s ago), %s: %v\n\n", label, cache.FetchedAt.In(cfg.DisplayLocation()).Format("01/02 15:04"), now.Sub(cache.FetchedAt).Round(time.Minute), calendar.Classify(fetchErr), fetchErr)
+
+	return statusRange(cfg, cache.Events, now)
+}
+
+// statusRange returns the events overlapping the status lookback and lookahead.
+func statusRange(cfg *config.Config, events []calendar.Event, now time.Time) []calendar.Event {
+	start, end := now.Add(-cfg.StatusLookback.Duration), now.Add(cfg.StatusLookahead.Duration)
+	var result []calendar.Event
+	for _, event := range events {
+		if event.StartTime.Before(end) && event.EndTime.After(start) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func printMeeting(label string, event *calendar.Event, timeStatus string, loc *time.Location) {
 	const tmpl = `%s:
-  Title:  %s
-  Time:   %s %s
-  Status: %s
-  Meet:   %s`
-	fmt.Printf(tmpl+"\n", label, event.Title, event.StartTime.Format("15:04"), timeStatus, event.ResponseStatus, event.MeetLink)
+  Title:    %s
+  Time:     %s %s
+  Status:   %s
+  Calendar: %s
+  Join:     %s`
+	fmt.Printf(tmpl+"\n", label, event.Title, event.StartTime.In(loc).Format("15:04 MST"), timeStatus, event.ResponseStatus, calendarLabel(event), event.JoinURL)
+
+	if event.Organizer.Email != "" {
+		fmt.Printf("  Organizer: %s\n", personLabel(event.Organizer))
+	}
+	if len(event.Attendees) > 0 {
+		fmt.Printf("  Guests:   %s\n", guestSummary(event.Attendees))
+	}
+	for _, dialIn := range event.DialIns {
+		if dialIn.Type != "phone" {
+			continue
+		}
+		if dialIn.PIN != "" {
+			fmt.Printf("  Dial-in:  %s (PIN %s)\n", dialIn.Label, dialIn.PIN)
+		} else {
+			fmt.Printf("  Dial-in:  %s\n", dialIn.Label)
+		}
+	}
+	if event.HTMLLink != "" {
+		fmt.Printf("  Details:  %s\n", event.HTMLLink)
+	}
+}
+
+func personLabel(p calendar.Person) string {
+	if p.Name != "" {
+		return fmt.Sprintf("%s <%s>", p.Name, p.Email)
+	}
+	return p.Email
+}
+
+// guestSummary returns e.g. "5 (3 accepted, 1 declined, 1 awaiting)".
+func guestSummary(attendees []calendar.Attendee) string {
+	counts := make(map[string]int)
+	for _, a := range attendees {
+		counts[a.ResponseStatus]++
+	}
+
+	var parts []string
+	for _, status := range []struct{ key, label string }{
+		{"accepted", "accepted"},
+		{"tentative", "maybe"},
+		{"declined", "declined"},
+		{"needsAction", "awaiting"},
+	} {
+		if n := counts[status.key]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status.label))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", len(attendees), strings.Join(parts, ", "))
+}
+
+func calendarLabel(event *calendar.Event) string {
+	label := event.CalendarName
+	if label == "" {
+		label = event.CalendarID
+	}
+	if event.Account != "" {
+		label += " (" + event.Account + ")"
+	}
+	return label
 }
 
 func formatEventStatus(startTime, now time.Time) string {
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
 	"github.com/knwoop/ooi/internal/launchd"
 	"github.com/spf13/cobra"
 )
@@ -11,25 +14,70 @@ import (
 var uninstallCmd = &cobra.Command{
 	Use:   "uninstall",
 	Short: "Uninstall launchd service",
-	Long:  "Remove ooi from launchd and stop auto-start on login.",
+	Long:  "Remove ooi from launchd and stop auto-start on login.\nWith --purge, also revoke Google access and delete all tokens, settings and cached data.",
 	Run: func(cmd *cobra.Command, args []string) {
-		if !launchd.IsInstalled() {
+		purge, _ := cmd.Flags().GetBool("purge")
+
+		if launchd.IsInstalled() {
+			fmt.Println("Uninstalling launchd service...")
+
+			if err := launchd.Uninstall(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to uninstall: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println("Service uninstalled successfully!")
+			fmt.Println("ooi will no longer start automatically.")
+		} else {
 			fmt.Println("Service is not installed.")
-			return
 		}
 
-		fmt.Println("Uninstalling launchd service...")
+		if purge {
+			purgeData()
+		}
+	},
+}
+
+// purgeData revokes and deletes every token, including those kept outside the
+// config directory by the OS keyring, then removes the config directory.
+// An invalid config falls back to the defaults so that purging still works.
+func purgeData() {
+	ctx := context.Background()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config, using defaults: %v\n", err)
+		cfg = config.Default()
+	}
 
-		if err := launchd.Uninstall(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to uninstall: %v\n", err)
-			os.Exit(1)
+	if store, err := cfg.TokenStore.Open(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open token store: %v\n", err)
+	} else {
+		for _, account := range googleAccounts(cfg) {
+			if err := revokeGoogle(ctx, store, account); err != nil {
+				// Still remove the token locally; access can be removed at https://myaccount.google.com/permissions
+				fmt.Fprintf(os.Stderr, "Google (%s): %v\n", accountLabel(account), err)
+				store.Delete(calendar.TokenKey(account))
+			}
 		}
+		removeMicrosoftToken(store)
+		if err := removeCalDAVCredentials(cfg, store); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove CalDAV passwords: %v\n", err)
+		}
+	}
 
-		fmt.Println("Service uninstalled successfully!")
-		fmt.Println("ooi will no longer start automatically.")
-	},
+	configDir, err := calendar.ConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.RemoveAll(configDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove %s: %v\n", configDir, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %s\n", configDir)
 }
 
 func init() {
+	uninstallCmd.Flags().Bool("purge", false, "also revoke Google access and delete tokens, config and cache")
 	rootCmd.AddCommand(uninstallCmd)
 }
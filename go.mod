@@ -3,9 +3,12 @@ module github.com/knwoop/ooi
 go 1.25.1
 
 require (
+	fyne.io/systray v1.12.0
+	github.com/BurntSushi/toml v1.6.0
 	github.com/google/go-cmp v0.7.0
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/oauth2 v0.34.0
+	golang.org/x/term v0.39.0
 	google.golang.org/api v0.263.0
 )
 
@@ -13,7 +16,6 @@ require (
 	cloud.google.com/go/auth v0.18.1 // indirect
 	cloud.google.com/go/auth/oauth2adapt v0.2.8 // indirect
 	cloud.google.com/go/compute/metadata v0.9.0 // indirect
-	fyne.io/systray v1.12.0 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
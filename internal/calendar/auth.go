@@ -1,34 +1,118 @@
 package calendar
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os/exec"
 	"runtime"
+	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
 )
 
-const callbackPort = 8085
+// DefaultAuthTimeout is how long Authenticate waits for the user to finish in the browser.
+const DefaultAuthTimeout = 5 * time.Minute
+
+type AuthOptions struct {
+	// Port is the loopback port of the redirect URL. Zero picks a free port.
+	Port int
+	// Timeout limits how long to wait for the browser. Zero means DefaultAuthTimeout.
+	Timeout time.Duration
+	// NoBrowser prints the URL instead of opening a browser and reads the
+	// redirect URL or code pasted into Input, for use over SSH
+	NoBrowser bool
+	Input     io.Reader
+	// RSVP also requests permission to respond to invitations
+	RSVP bool
+}
 
-func Authenticate(ctx context.Context) (*oauth2.Token, error) {
-	config, err := GetOAuthConfig()
+// Authenticate runs the OAuth flow in the browser for the account. Empty means the default account.
+func Authenticate(ctx context.Context, account string, opts AuthOptions) (*oauth2.Token, error) {
+	config, err := GetOAuthConfig(account)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get OAuth config: %w", err)
 	}
 
-	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", callbackPort)
+	if opts.RSVP {
+		config.Scopes = append(config.Scopes, calendar.CalendarEventsScope)
+	}
+
+	// Incremental authorization: the new token keeps the scopes granted before
+	authOpts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("include_granted_scopes", "true")}
+	if account != "" {
+		// Let the user pick which signed-in Google identity to authorize
+		authOpts = append(authOpts, oauth2.SetAuthURLParam("prompt", "select_account consent"))
+	}
+
+	return authorizeLoopback(ctx, config, opts, authOpts, func(authURL string) {
+		if opts.NoBrowser {
+			fmt.Printf("Open this URL in a browser on any machine:\n%s\n\n", authURL)
+			fmt.Println("After signing in, the browser is redirected to a 127.0.0.1 page that may fail to load.")
+			fmt.Print("Paste the URL from its address bar (or just the code) here: ")
+			return
+		}
+		fmt.Printf("Opening browser for authentication...\n")
+		if err := openBrowser(authURL); err != nil {
+			fmt.Printf("Please open this URL manually:\n%s\n", authURL)
+		}
+	})
+}
+
+// authorizeLoopback runs the authorization code flow with PKCE, receiving the
+// code on a one-off server bound to 127.0.0.1. open is called with the URL the
+// user must visit.
+func authorizeLoopback(ctx context.Context, config *oauth2.Config, opts AuthOptions, authOpts []oauth2.AuthCodeOption, open func(authURL string)) (*oauth2.Token, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultAuthTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth callback: %w", err)
+	}
+
+	cfg := *config
+	cfg.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	state := rand.Text()
+	verifier := oauth2.GenerateVerifier()
 
 	codeCh := make(chan string, 1)
 	errCh := make(chan error, 1)
 
-	server := &http.Server{Addr: fmt.Sprintf(":%d", callbackPort)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		// Ignore requests that did not come from our authorization request
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		}
+
+		if errCode := query.Get("error"); errCode != "" {
+			http.Error(w, "Authentication failed: "+errCode, http.StatusForbidden)
+			select {
+			case errCh <- authorizationError(errCode, query.Get("error_description")):
+			default:
+			}
+			return
+		}
 
-	http.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
+		code := query.Get("code")
 		if code == "" {
-			errCh <- fmt.Errorf("no code in callback")
 			http.Error(w, "No code received", http.StatusBadRequest)
 			return
 		}
@@ -36,35 +120,69 @@ func Authenticate(ctx context.Context) (*oauth2.Token, error) {
 		w.Header().Set("Content-Type", "text/html")
 		fmt.Fprint(w, `<html><body><h1>Authentication successful!</h1><p>You can close this window.</p></body></html>`)
 
-		codeCh <- code
+		select {
+		case codeCh <- code:
+		default:
+		}
 	})
 
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
 	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			errCh <- err
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			select {
+			case errCh <- err:
+			default:
+			}
 		}
 	}()
+	defer func() {
+		// Let the browser receive the response before stopping
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
 
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Opening browser for authentication...\n")
-	if err := openBrowser(authURL); err != nil {
-		fmt.Printf("Please open this URL manually:\n%s\n", authURL)
+	if opts.NoBrowser && opts.Input != nil {
+		go func() {
+			scanner := bufio.NewScanner(opts.Input)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line == "" {
+					continue
+				}
+				code, err := parsePastedCode(line, state)
+				if err != nil {
+					select {
+					case errCh <- err:
+					default:
+					}
+					return
+				}
+				select {
+				case codeCh <- code:
+				default:
+				}
+				return
+			}
+		}()
 	}
 
+	authOpts = append(authOpts, oauth2.S256ChallengeOption(verifier))
+	open(cfg.AuthCodeURL(state, authOpts...))
+
 	var code string
 	select {
 	case code = <-codeCh:
 	case err := <-errCh:
-		server.Shutdown(ctx)
 		return nil, err
 	case <-ctx.Done():
-		server.Shutdown(ctx)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s waiting for authentication in the browser", timeout)
+		}
 		return nil, ctx.Err()
 	}
 
-	server.Shutdown(ctx)
-
-	token, err := config.Exchange(ctx, code)
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
@@ -72,6 +190,41 @@ func Authenticate(ctx context.Context) (*oauth2.Token, error) {
 	return token, nil
 }
 
+// parsePastedCode returns the code from a pasted redirect URL, checking its
+// state, or the input itself if it is a bare code.
+func parsePastedCode(input, state string) (string, error) {
+	if !strings.Contains(input, "?") {
+		return input, nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	query := u.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+		return "", errors.New("the pasted URL is from a different authentication attempt")
+	}
+	if errCode := query.Get("error"); errCode != "" {
+		return "", authorizationError(errCode, query.Get("error_description"))
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("no code in the pasted URL")
+	}
+	return code, nil
+}
+
+func authorizationError(code, description string) error {
+	if code == "access_denied" {
+		return errors.New("access was denied in the browser")
+	}
+	if description != "" {
+		return fmt.Errorf("authorization failed: %s: %s", code, description)
+	}
+	return fmt.Errorf("authorization failed: %s", code)
+}
+
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -0,0 +1,149 @@
+package calendar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+// browse simulates the user's browser: the provider redirects to the callback with params.
+func browse(t *testing.T, authURL string, params url.Values) int {
+	t.Helper()
+	u, _ := url.Parse(authURL)
+	resp, err := http.Get(u.Query().Get("redirect_uri") + "?" + params.Encode())
+	if err != nil {
+		t.Errorf("callback request failed: %v", err)
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthorizeLoopback(t *testing.T) {
+	var challenge string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth", TokenURL: tokenServer.URL}}
+
+	statusCh := make(chan []int, 1)
+	token, err := authorizeLoopback(context.Background(), config, AuthOptions{}, nil, func(authURL string) {
+		u, _ := url.Parse(authURL)
+		query := u.Query()
+		challenge = query.Get("code_challenge")
+		if !strings.HasPrefix(query.Get("redirect_uri"), "http://127.0.0.1:") {
+			t.Errorf("redirect_uri is not loopback: %s", query.Get("redirect_uri"))
+		}
+		if diff := cmp.Diff("S256", query.Get("code_challenge_method")); diff != "" {
+			t.Errorf("code_challenge_method mismatch (-want +got):\n%s", diff)
+		}
+
+		go func() {
+			forged := browse(t, authURL, url.Values{"state": {"forged"}, "code": {"evil"}})
+			valid := browse(t, authURL, url.Values{"state": {query.Get("state")}, "code": {"the-code"}})
+			statusCh <- []int{forged, valid}
+		}()
+	})
+	if err != nil {
+		t.Fatalf("authorizeLoopback failed: %v", err)
+	}
+
+	if diff := cmp.Diff("refresh", token.RefreshToken); diff != "" {
+		t.Errorf("refresh token mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{http.StatusBadRequest, http.StatusOK}, <-statusCh); diff != "" {
+		t.Errorf("callback statuses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuthorizeLoopbackDenied(t *testing.T) {
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth"}}
+
+	_, err := authorizeLoopback(context.Background(), config, AuthOptions{}, nil, func(authURL string) {
+		u, _ := url.Parse(authURL)
+		go browse(t, authURL, url.Values{"state": {u.Query().Get("state")}, "error": {"access_denied"}})
+	})
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected an access denied error, got %v", err)
+	}
+}
+
+func TestAuthorizeLoopbackTimeout(t *testing.T) {
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth"}}
+
+	_, err := authorizeLoopback(context.Background(), config, AuthOptions{Timeout: 50 * time.Millisecond}, nil, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestAuthorizeLoopbackPastedURL(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth", TokenURL: tokenServer.URL}}
+
+	input, paste := io.Pipe()
+	opts := AuthOptions{NoBrowser: true, Input: input}
+	token, err := authorizeLoopback(context.Background(), config, opts, nil, func(authURL string) {
+		u, _ := url.Parse(authURL)
+		redirect := u.Query().Get("redirect_uri") + "?state=" + u.Query().Get("state") + "&code=the-code"
+		go fmt.Fprintf(paste, "\n%s\n", redirect)
+	})
+	if err != nil {
+		t.Fatalf("authorizeLoopback failed: %v", err)
+	}
+	if diff := cmp.Diff("refresh", token.RefreshToken); diff != "" {
+		t.Errorf("refresh token mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParsePastedCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "redirect URL", input: "http://127.0.0.1:53682/callback?state=s1&code=4/abc&scope=x", want: "4/abc"},
+		{name: "bare code", input: "4/abc", want: "4/abc"},
+		{name: "state mismatch", input: "http://127.0.0.1:53682/callback?state=other&code=4/abc", wantErr: true},
+		{name: "access denied", input: "http://127.0.0.1:53682/callback?state=s1&error=access_denied", wantErr: true},
+		{name: "no code", input: "http://127.0.0.1:53682/callback?state=s1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePastedCode(tt.input, "s1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePastedCode error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("code mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
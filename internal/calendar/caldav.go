@@ -0,0 +1,288 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/knwoop/ooi/internal/ical"
+	"golang.org/x/oauth2"
+)
+
+// CalDAVCredentials is an app password for a CalDAV server, kept in the token store.
+type CalDAVCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CalDAVSource reads events from a CalDAV calendar collection (Fastmail, iCloud, Nextcloud).
+type CalDAVSource struct {
+	calendarURL string
+	name        string
+	email       string
+	credentials CalDAVCredentials
+	httpClient  *http.Client
+}
+
+type CalDAVOptions struct {
+	// Name is shown as the calendar name and identifies the stored credentials.
+	Name string
+	// Email identifies you among the attendees to read your response status.
+	Email string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+func NewCalDAVSource(calendarURL string, credentials CalDAVCredentials, opts CalDAVOptions) *CalDAVSource {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return &CalDAVSource{
+		calendarURL: calendarURL,
+		name:        opts.Name,
+		email:       opts.Email,
+		credentials: credentials,
+		httpClient:  httpClient,
+	}
+}
+
+func (s *CalDAVSource) sourceError(err error) error {
+	return &SourceError{Source: s.name, AuthCommand: "ooi auth caldav --name " + s.name, Err: err}
+}
+
+const calendarQueryTpl = `<?xml version="1.0" encoding="utf-8" ?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data/>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%s" end="%s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func (s *CalDAVSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
+	now := time.Now()
+	start := now.Add(-lookback)
+	end := now.Add(lookahead)
+
+	const layout = "20060102T150405Z"
+	body := fmt.Sprintf(calendarQueryTpl, start.UTC().Format(layout), end.UTC().Format(layout))
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", s.calendarURL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(s.credentials.Username, s.credentials.Password)
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, s.sourceError(fmt.Errorf("failed to query calendar: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, s.sourceError(fmt.Errorf("failed to query calendar: %w", newHTTPError(resp)))
+	}
+
+	data, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var ms multistatus
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var result []Event
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstats {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+
+			// Servers may return the recurring master event without expanding it,
+			// so expansion is done locally.
+			cal, err := ical.ParseCalendar(bytes.NewReader([]byte(ps.Prop.CalendarData)), time.Local)
+			if err != nil {
+				continue
+			}
+			calendarName := s.name
+			if calendarName == "" {
+				calendarName = cal.Name
+			}
+			for _, item := range cal.Expand(start, end) {
+				event, ok := convertICalEvent(item, s.email)
+				if !ok {
+					continue
+				}
+				event.CalendarID = s.calendarID()
+				event.CalendarName = calendarName
+				result = append(result, event)
+			}
+		}
+	}
+
+	return mergeEvents(result), nil
+}
+
+func (s *CalDAVSource) calendarID() string {
+	if s.name != "" {
+		return s.name
+	}
+	return s.calendarURL
+}
+
+// CalDAVKey returns the key of a CalDAV calendar's credentials in a TokenStore.
+func CalDAVKey(name string) string {
+	return "caldav-" + name
+}
+
+// caldavTokenType marks CalDAV credentials in a TokenStore. They are kept as the
+// access token in HTTP Basic form, so they get the same protection as OAuth tokens.
+const caldavTokenType = "Basic"
+
+// CalDAVCredentialsPath returns caldav.json, where earlier versions kept app
+// passwords in plaintext. Credentials found there are moved to the token store.
+func CalDAVCredentialsPath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "caldav.json"), nil
+}
+
+// LoadCalDAVCredentials returns the stored app password for the named CalDAV calendar.
+func LoadCalDAVCredentials(store TokenStore, name string) (CalDAVCredentials, error) {
+	token, err := store.Load(CalDAVKey(name))
+	switch {
+	case err == nil:
+		return decodeCalDAVToken(token)
+	case !errors.Is(err, ErrTokenNotFound):
+		return CalDAVCredentials{}, err
+	}
+
+	legacy, err := loadLegacyCalDAVCredentials()
+	if err != nil {
+		return CalDAVCredentials{}, err
+	}
+	creds, ok := legacy[name]
+	if !ok {
+		return CalDAVCredentials{}, fmt.Errorf("no credentials for CalDAV calendar %q, run 'ooi auth caldav --name %s'", name, name)
+	}
+	if err := SaveCalDAVCredentials(store, name, creds); err != nil {
+		return CalDAVCredentials{}, fmt.Errorf("failed to migrate CalDAV credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// SaveCalDAVCredentials stores the app password for the named CalDAV calendar
+// and removes it from the legacy caldav.json.
+func SaveCalDAVCredentials(store TokenStore, name string, creds CalDAVCredentials) error {
+	basic := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	if err := store.Save(CalDAVKey(name), &oauth2.Token{AccessToken: basic, TokenType: caldavTokenType}); err != nil {
+		return err
+	}
+	return removeLegacyCalDAVCredentials(name)
+}
+
+// DeleteCalDAVCredentials removes the credentials of the named CalDAV calendar.
+func DeleteCalDAVCredentials(store TokenStore, name string) error {
+	if err := store.Delete(CalDAVKey(name)); err != nil {
+		return err
+	}
+	return removeLegacyCalDAVCredentials(name)
+}
+
+func decodeCalDAVToken(token *oauth2.Token) (CalDAVCredentials, error) {
+	data, err := base64.StdEncoding.DecodeString(token.AccessToken)
+	if token.TokenType != caldavTokenType || err != nil {
+		return CalDAVCredentials{}, fmt.Errorf("invalid CalDAV credentials in token store")
+	}
+	username, password, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return CalDAVCredentials{}, fmt.Errorf("invalid CalDAV credentials in token store")
+	}
+	return CalDAVCredentials{Username: username, Password: password}, nil
+}
+
+func loadLegacyCalDAVCredentials() (map[string]CalDAVCredentials, error) {
+	path, err := CalDAVCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]CalDAVCredentials)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	return all, nil
+}
+
+// removeLegacyCalDAVCredentials removes name from caldav.json, and the file once
+// it is empty. The file is replaced atomically so other entries survive a crash.
+func removeLegacyCalDAVCredentials(name string) error {
+	all, err := loadLegacyCalDAVCredentials()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[name]; !ok {
+		return nil
+	}
+	delete(all, name)
+
+	path, err := CalDAVCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove credentials file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
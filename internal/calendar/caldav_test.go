@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCalDAVSource(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "REPORT" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "app-password" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Depth") != "1" {
+			http.Error(w, "missing depth", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "time-range") {
+			http.Error(w, "missing time-range", http.StatusBadRequest)
+			return
+		}
+
+		var data strings.Builder
+		xml.EscapeText(&data, []byte(testICS(start)))
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/dav/calendars/me/default/sync-1.ics</d:href>
+    <d:propstat>
+      <d:prop>
+        <d:getetag>"1"</d:getetag>
+        <cal:calendar-data>%s</cal:calendar-data>
+      </d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`, data.String())
+	}))
+	defer server.Close()
+
+	creds := CalDAVCredentials{Username: "me@example.com", Password: "app-password"}
+	source := NewCalDAVSource(server.URL+"/dav/calendars/me/default/", creds, CalDAVOptions{
+		Name:  "Fastmail",
+		Email: "me@example.com",
+	})
+
+	got, err := source.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+
+	var summaries []string
+	for _, e := range got {
+		summaries = append(summaries, fmt.Sprintf("%s/%s/%s/%s", e.Title, e.ResponseStatus, e.Provider, e.CalendarName))
+	}
+	want := []string{"Sync/tentative/teams/Fastmail"}
+	if diff := cmp.Diff(want, summaries); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	bad := NewCalDAVSource(server.URL, CalDAVCredentials{Username: "me@example.com", Password: "wrong"}, CalDAVOptions{Name: "Fastmail"})
+	_, err = bad.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+	if err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+	var sourceErr *SourceError
+	if !errors.As(err, &sourceErr) || sourceErr.AuthCommand != "ooi auth caldav --name Fastmail" {
+		t.Errorf("expected a SourceError with the auth command, got %v", err)
+	}
+	if diff := cmp.Diff(KindTokenExpired, Classify(err)); diff != "" {
+		t.Errorf("kind mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalDAVCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := &FileTokenStore{Dir: t.TempDir()}
+
+	// Passwords from caldav.json are moved into the token store
+	path, err := CalDAVCredentialsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"Fastmail":{"username":"me@fastmail.com","password":"app:password"},"iCloud":{"username":"me@icloud.com","password":"secret"}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := LoadCalDAVCredentials(store, "Fastmail")
+	if err != nil {
+		t.Fatalf("LoadCalDAVCredentials failed: %v", err)
+	}
+	want := CalDAVCredentials{Username: "me@fastmail.com", Password: "app:password"}
+	if diff := cmp.Diff(want, creds); diff != "" {
+		t.Errorf("credentials mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := store.Load(CalDAVKey("Fastmail")); err != nil {
+		t.Errorf("expected the credentials in the store, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "Fastmail") || !strings.Contains(string(data), "iCloud") {
+		t.Errorf("caldav.json should only keep iCloud, got %s", data)
+	}
+
+	// The file is removed with its last entry
+	if err := SaveCalDAVCredentials(store, "iCloud", CalDAVCredentials{Username: "me@icloud.com", Password: "new"}); err != nil {
+		t.Fatalf("SaveCalDAVCredentials failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected caldav.json to be removed, got %v", err)
+	}
+
+	if err := DeleteCalDAVCredentials(store, "Fastmail"); err != nil {
+		t.Fatalf("DeleteCalDAVCredentials failed: %v", err)
+	}
+	if _, err := LoadCalDAVCredentials(store, "Fastmail"); err == nil {
+		t.Error("expected an error after deleting the credentials")
+	}
+}
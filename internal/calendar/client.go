@@ -2,10 +2,14 @@ package calendar
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -24,15 +28,79 @@ var responseStatusPriority = map[string]int{
 
 type Event struct {
 	ID             string
+	ICalUID        string
 	Title          string
 	StartTime      time.Time
 	EndTime        time.Time
-	MeetLink       string
+	Provider       ConferenceProvider
+	JoinURL        string
 	ResponseStatus string // accepted, tentative, needsAction, declined
+	CalendarID     string
+	CalendarName   string
+	Reminders      []time.Duration // Lead times before StartTime to alert at (e.g. from VALARM)
+	AllDay         bool            // StartTime and EndTime are midnights in the calendar's time zone
+	Account        string          // Google account the event was read from; empty for the default account
+	Organizer      Person
+	Attendees      []Attendee
+	Optional       bool   // You are an optional attendee
+	Description    string // Plain text or HTML, as stored by the source
+	Location       string
+	HTMLLink       string // Link to the event in the calendar's web UI
+	ColorID        string // Google color ID or the source's color value
+	EventType      string // default, focusTime, outOfOffice, workingLocation
+	Transparency   string // opaque (busy) or transparent (free)
+	Attachments    []Attachment
+	DialIns        []DialIn // Phone and SIP entry points of the conference
+}
+
+type Person struct {
+	Email string
+	Name  string
+	Self  bool
+}
+
+type Attendee struct {
+	Person
+	ResponseStatus string // accepted, tentative, needsAction, declined
+	Optional       bool
+	Organizer      bool
+}
+
+type Attachment struct {
+	Title    string
+	URL      string
+	MimeType string
+}
+
+type DialIn struct {
+	Type  string // phone, sip or more
+	URI   string // e.g. tel:+1-555-0100
+	Label string
+	PIN   string
 }
 
 type Client struct {
-	service *calendar.Service
+	service     *calendar.Service
+	calendarIDs []string
+	account     string
+	reminders   bool
+
+	mu        sync.Mutex
+	email     string
+	calendars map[string]*calendarCache
+}
+
+type ClientOptions struct {
+	// Account names the Google account. Empty means the default account.
+	Account string
+	// Email is the account's address, used as authuser in Meet links.
+	// If empty for a named account, it is looked up from the primary calendar.
+	Email string
+	// Store receives refreshed tokens
+	Store TokenStore
+	// Reminders sets Event.Reminders from the events' popup reminders,
+	// or the calendar's default reminders for events that use them.
+	Reminders bool
 }
 
 func ConfigDir() (string, error) {
@@ -43,93 +111,341 @@ func ConfigDir() (string, error) {
 	return filepath.Join(home, ".config", "ooi"), nil
 }
 
-func NewClient(ctx context.Context, token *oauth2.Token) (*Client, error) {
-	configDir, err := ConfigDir()
+// NewClient creates a client that watches the given calendar IDs.
+// If no calendar IDs are given, only the primary calendar is watched.
+func NewClient(ctx context.Context, token *oauth2.Token, calendarIDs []string, opts ClientOptions) (*Client, error) {
+	config, err := GetOAuthConfig(opts.Account)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config dir: %w", err)
+		return nil, err
 	}
 
-	credentialsPath := filepath.Join(configDir, "credentials.json")
-	b, err := os.ReadFile(credentialsPath)
+	client := PersistentClient(ctx, config, opts.Store, TokenKey(opts.Account), token)
+	service, err := calendar.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read credentials.json: %w", err)
+		return nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
 
-	config, err := google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse credentials: %w", err)
-	}
+	c := newClient(service, calendarIDs)
+	c.account = opts.Account
+	c.email = opts.Email
+	c.reminders = opts.Reminders
+	return c, nil
+}
 
-	client := config.Client(ctx, token)
-	service, err := calendar.NewService(ctx, option.WithHTTPClient(client))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+func newClient(service *calendar.Service, calendarIDs []string) *Client {
+	if len(calendarIDs) == 0 {
+		calendarIDs = []string{"primary"}
 	}
 
-	return &Client{service: service}, nil
+	return &Client{
+		service:     service,
+		calendarIDs: calendarIDs,
+		calendars:   make(map[string]*calendarCache),
+	}
 }
 
 func (c *Client) GetUpcomingEvents(ctx context.Context, duration time.Duration) ([]Event, error) {
 	return c.GetEventsInRange(ctx, 0, duration)
 }
 
+// GetEventsInRange returns events overlapping [now-lookback, now+lookahead] from all watched calendars.
+// Calendars are kept in sync incrementally, so repeated calls only download changes.
 func (c *Client) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
 	now := time.Now()
 	start := now.Add(-lookback)
 	end := now.Add(lookahead)
 
-	events, err := c.service.Events.List("primary").
-		TimeMin(start.Format(time.RFC3339)).
-		TimeMax(end.Format(time.RFC3339)).
-		SingleEvents(true).
-		OrderBy("startTime").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.account != "" && c.email == "" {
+		if err := c.resolveEmail(ctx); err != nil {
+			log.Printf("Failed to look up email of account %s: %v", c.account, err)
+		}
 	}
 
 	var result []Event
-	for _, item := range events.Items {
-		if item.HangoutLink == "" {
+	var errs []error
+	for _, calendarID := range c.calendarIDs {
+		cache, err := c.syncCalendar(ctx, calendarID, start, end)
+		if err != nil {
+			err = fmt.Errorf("failed to fetch events from %s: %w", calendarID, err)
+			if c.account != "" {
+				err = &SourceError{Source: "account " + c.account, AuthCommand: "ooi auth --account " + c.account, Err: err}
+			}
+			errs = append(errs, err)
 			continue
 		}
 
-		// Skip declined events
-		responseStatus := getResponseStatus(item)
-		if responseStatus == "declined" {
-			continue
+		for _, item := range cache.items {
+			event, ok := convertEvent(item, calendarID, cache.name, cache.location)
+			if !ok {
+				continue
+			}
+			if !event.StartTime.Before(end) || !event.EndTime.After(start) {
+				continue
+			}
+			event.Account = c.account
+			if c.reminders {
+				event.Reminders = eventReminders(item, cache.defaultReminders)
+			}
+			if c.email != "" && event.Provider == ProviderMeet {
+				event.JoinURL = withAuthUser(event.JoinURL, c.email)
+			}
+			result = append(result, event)
 		}
+	}
 
-		startTime, err := parseEventTime(item.Start)
-		if err != nil {
+	// A calendar that fails, e.g. one no longer shared, does not hide the others
+	switch {
+	case len(errs) == len(c.calendarIDs):
+		return nil, errors.Join(errs...)
+	case len(errs) > 0:
+		return mergeEvents(result), &PartialError{Err: errors.Join(errs...)}
+	}
+	return mergeEvents(result), nil
+}
+
+// OutOfOffice returns out-of-office events you own on the watched calendars.
+func (c *Client) OutOfOffice(start, end time.Time) []Period {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result []Period
+	for _, cache := range c.calendars {
+		for _, item := range cache.items {
+			if item.EventType != "outOfOffice" || item.Organizer == nil || !item.Organizer.Self {
+				continue
+			}
+			startTime, err := parseEventTime(item.Start, cache.location)
+			if err != nil {
+				continue
+			}
+			endTime, err := parseEventTime(item.End, cache.location)
+			if err != nil {
+				continue
+			}
+			if !startTime.Before(end) || !endTime.After(start) {
+				continue
+			}
+			result = append(result, Period{Title: item.Summary, Start: startTime, End: endTime})
+		}
+	}
+	return result
+}
+
+// resolveEmail looks up the account's address, which is the ID of its primary calendar.
+func (c *Client) resolveEmail(ctx context.Context) error {
+	cal, err := c.service.Calendars.Get("primary").Fields("id").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	c.email = cal.Id
+	return nil
+}
+
+// withAuthUser makes Google open link as the given account when several are signed in.
+func withAuthUser(link, email string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	q := u.Query()
+	q.Set("authuser", email)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// convertEvent maps a Google Calendar event to an Event.
+// It returns false for events without a conference link or that you declined.
+// All-day events are interpreted in loc, the calendar's time zone.
+func convertEvent(item *calendar.Event, calendarID, calendarName string, loc *time.Location) (Event, bool) {
+	provider, joinURL := extractConference(item)
+	if joinURL == "" {
+		return Event{}, false
+	}
+
+	// Skip declined events
+	responseStatus := getResponseStatus(item)
+	if responseStatus == "declined" {
+		return Event{}, false
+	}
+
+	startTime, err := parseEventTime(item.Start, loc)
+	if err != nil {
+		return Event{}, false
+	}
+
+	endTime, err := parseEventTime(item.End, loc)
+	if err != nil {
+		return Event{}, false
+	}
+
+	return Event{
+		ID:             item.Id,
+		ICalUID:        item.ICalUID,
+		Title:          item.Summary,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Provider:       provider,
+		JoinURL:        joinURL,
+		ResponseStatus: responseStatus,
+		CalendarID:     calendarID,
+		CalendarName:   calendarName,
+		AllDay:         item.Start.DateTime == "" && item.Start.Date != "",
+		Organizer:      organizer(item),
+		Attendees:      attendees(item),
+		Optional:       isOptional(item),
+		Description:    item.Description,
+		Location:       item.Location,
+		HTMLLink:       item.HtmlLink,
+		ColorID:        item.ColorId,
+		EventType:      eventType(item.EventType),
+		Transparency:   transparency(item.Transparency),
+		Attachments:    attachments(item),
+		DialIns:        dialIns(item),
+	}, true
+}
+
+// eventReminders returns the popup reminders of the event, or defaults when it uses
+// the calendar's default reminders.
+func eventReminders(item *calendar.Event, defaults []time.Duration) []time.Duration {
+	if item.Reminders == nil || item.Reminders.UseDefault {
+		return defaults
+	}
+	return popupReminders(item.Reminders.Overrides)
+}
+
+// popupReminders returns the lead times of the popup reminders; email reminders are ignored.
+func popupReminders(reminders []*calendar.EventReminder) []time.Duration {
+	var leads []time.Duration
+	for _, r := range reminders {
+		if r.Method == "popup" {
+			leads = append(leads, time.Duration(r.Minutes)*time.Minute)
+		}
+	}
+	return leads
+}
+
+func organizer(item *calendar.Event) Person {
+	if item.Organizer == nil {
+		return Person{}
+	}
+	return Person{Email: item.Organizer.Email, Name: item.Organizer.DisplayName, Self: item.Organizer.Self}
+}
+
+func attendees(item *calendar.Event) []Attendee {
+	var result []Attendee
+	for _, a := range item.Attendees {
+		result = append(result, Attendee{
+			Person:         Person{Email: a.Email, Name: a.DisplayName, Self: a.Self},
+			ResponseStatus: a.ResponseStatus,
+			Optional:       a.Optional,
+			Organizer:      a.Organizer,
+		})
+	}
+	return result
+}
+
+func attachments(item *calendar.Event) []Attachment {
+	var result []Attachment
+	for _, a := range item.Attachments {
+		result = append(result, Attachment{Title: a.Title, URL: a.FileUrl, MimeType: a.MimeType})
+	}
+	return result
+}
+
+func dialIns(item *calendar.Event) []DialIn {
+	if item.ConferenceData == nil {
+		return nil
+	}
+	var result []DialIn
+	for _, ep := range item.ConferenceData.EntryPoints {
+		if ep.EntryPointType == "video" {
 			continue
 		}
+		pin := ep.Pin
+		if pin == "" {
+			pin = ep.AccessCode
+		}
+		result = append(result, DialIn{Type: ep.EntryPointType, URI: ep.Uri, Label: ep.Label, PIN: pin})
+	}
+	return result
+}
 
-		endTime, err := parseEventTime(item.End)
-		if err != nil {
+func isOptional(item *calendar.Event) bool {
+	for _, attendee := range item.Attendees {
+		if attendee.Self {
+			return attendee.Optional
+		}
+	}
+	return false
+}
+
+func eventType(t string) string {
+	if t == "" {
+		return "default"
+	}
+	return t
+}
+
+func transparency(t string) string {
+	if t == "" {
+		return "opaque"
+	}
+	return t
+}
+
+// ExcludeAllDay returns the events that are not all-day events.
+func ExcludeAllDay(events []Event) []Event {
+	var result []Event
+	for _, event := range events {
+		if !event.AllDay {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+type mergeKey struct {
+	uid       string
+	startTime int64
+}
+
+// mergeEvents de-duplicates events that appear on several calendars and sorts them.
+// The same invite shares an iCalUID across calendars, and instances of a recurring
+// event share it too, so the start time is part of the key.
+// When duplicated, the copy with the higher response status priority wins.
+func mergeEvents(events []Event) []Event {
+	var result []Event
+	index := make(map[mergeKey]int)
+	for _, event := range events {
+		uid := event.ICalUID
+		if uid == "" {
+			uid = event.CalendarID + "/" + event.ID
+		}
+		key := mergeKey{uid: uid, startTime: event.StartTime.Unix()}
+
+		if i, ok := index[key]; ok {
+			if responseStatusPriority[event.ResponseStatus] < responseStatusPriority[result[i].ResponseStatus] {
+				result[i] = event
+			}
 			continue
 		}
 
-		result = append(result, Event{
-			ID:             item.Id,
-			Title:          item.Summary,
-			StartTime:      startTime,
-			EndTime:        endTime,
-			MeetLink:       item.HangoutLink,
-			ResponseStatus: responseStatus,
-		})
+		index[key] = len(result)
+		result = append(result, event)
 	}
 
 	// Sort by start time, then by response status priority
-	sort.Slice(result, func(i, j int) bool {
+	sort.SliceStable(result, func(i, j int) bool {
 		if result[i].StartTime.Equal(result[j].StartTime) {
 			return responseStatusPriority[result[i].ResponseStatus] < responseStatusPriority[result[j].ResponseStatus]
 		}
 		return result[i].StartTime.Before(result[j].StartTime)
 	})
 
-	return result, nil
+	return result
 }
 
 func getResponseStatus(event *calendar.Event) string {
@@ -162,27 +478,47 @@ func (c *Client) GetNextMeetEvent(ctx context.Context) (*Event, error) {
 	return &events[0], nil
 }
 
-func parseEventTime(eventTime *calendar.EventDateTime) (time.Time, error) {
+// parseEventTime parses a start or end time. Date-only values (all-day events) are
+// midnight in the event's own time zone if set, otherwise in loc.
+func parseEventTime(eventTime *calendar.EventDateTime, loc *time.Location) (time.Time, error) {
 	if eventTime.DateTime != "" {
 		return time.Parse(time.RFC3339, eventTime.DateTime)
 	}
 	if eventTime.Date != "" {
-		return time.Parse("2006-01-02", eventTime.Date)
+		if eventTime.TimeZone != "" {
+			if eventLoc, err := time.LoadLocation(eventTime.TimeZone); err == nil {
+				loc = eventLoc
+			}
+		}
+		return time.ParseInLocation("2006-01-02", eventTime.Date, loc)
 	}
 	return time.Time{}, fmt.Errorf("no valid time found")
 }
 
-func GetOAuthConfig() (*oauth2.Config, error) {
+// GetOAuthConfig reads the OAuth client for the account. A named account uses
+// credentials-<account>.json if present and falls back to credentials.json.
+func GetOAuthConfig(account string) (*oauth2.Config, error) {
 	configDir, err := ConfigDir()
 	if err != nil {
 		return nil, err
 	}
 
 	credentialsPath := filepath.Join(configDir, "credentials.json")
+	if account != "" {
+		accountPath := filepath.Join(configDir, "credentials-"+account+".json")
+		if _, err := os.Stat(accountPath); err == nil {
+			credentialsPath = accountPath
+		}
+	}
+
 	b, err := os.ReadFile(credentialsPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read credentials.json: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(credentialsPath), err)
 	}
 
-	return google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
+	config, err := google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return config, nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/calendar/v3"
 )
 
 func TestResponseStatusPriority(t *testing.T) {
@@ -78,3 +79,152 @@ func TestEventSortByStartTimeThenStatus(t *testing.T) {
 		t.Errorf("event order mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestMergeEvents(t *testing.T) {
+	now := time.Now()
+	time1 := now.Add(10 * time.Minute)
+	time2 := now.Add(20 * time.Minute)
+
+	tests := []struct {
+		name   string
+		events []Event
+		want   []Event
+	}{
+		{
+			name: "same invite on two calendars keeps higher priority status",
+			events: []Event{
+				{ID: "a", ICalUID: "uid-1", StartTime: time1, ResponseStatus: "needsAction", CalendarID: "team"},
+				{ID: "a", ICalUID: "uid-1", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+			},
+			want: []Event{
+				{ID: "a", ICalUID: "uid-1", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+			},
+		},
+		{
+			name: "recurring instances are kept",
+			events: []Event{
+				{ID: "a_1", ICalUID: "uid-1", StartTime: time2, ResponseStatus: "accepted", CalendarID: "primary"},
+				{ID: "a_0", ICalUID: "uid-1", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+			},
+			want: []Event{
+				{ID: "a_0", ICalUID: "uid-1", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+				{ID: "a_1", ICalUID: "uid-1", StartTime: time2, ResponseStatus: "accepted", CalendarID: "primary"},
+			},
+		},
+		{
+			name: "events without iCalUID are not merged across calendars",
+			events: []Event{
+				{ID: "a", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+				{ID: "a", StartTime: time1, ResponseStatus: "tentative", CalendarID: "team"},
+			},
+			want: []Event{
+				{ID: "a", StartTime: time1, ResponseStatus: "accepted", CalendarID: "primary"},
+				{ID: "a", StartTime: time1, ResponseStatus: "tentative", CalendarID: "team"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeEvents(tt.events)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mergeEvents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseEventTimeAllDay(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 2026-03-08 is the DST transition day in New York and only 23 hours long
+	start, err := parseEventTime(&calendar.EventDateTime{Date: "2026-03-08"}, ny)
+	if err != nil {
+		t.Fatalf("parseEventTime failed: %v", err)
+	}
+	end, err := parseEventTime(&calendar.EventDateTime{Date: "2026-03-09"}, ny)
+	if err != nil {
+		t.Fatalf("parseEventTime failed: %v", err)
+	}
+
+	if diff := cmp.Diff("2026-03-08T00:00:00-05:00", start.Format(time.RFC3339)); diff != "" {
+		t.Errorf("start mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(23*time.Hour, end.Sub(start)); diff != "" {
+		t.Errorf("duration mismatch (-want +got):\n%s", diff)
+	}
+
+	// The event's own time zone takes precedence over the calendar's
+	tokyo, err := parseEventTime(&calendar.EventDateTime{Date: "2026-03-08", TimeZone: "Asia/Tokyo"}, ny)
+	if err != nil {
+		t.Fatalf("parseEventTime failed: %v", err)
+	}
+	if diff := cmp.Diff("2026-03-08T00:00:00+09:00", tokyo.Format(time.RFC3339)); diff != "" {
+		t.Errorf("start mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertEventDetails(t *testing.T) {
+	item := &calendar.Event{
+		Id:          "abc",
+		Summary:     "Design review",
+		HtmlLink:    "https://www.google.com/calendar/event?eid=abc",
+		HangoutLink: "https://meet.google.com/abc-defg-hij",
+		Description: "Agenda attached",
+		Location:    "Room 1",
+		ColorId:     "5",
+		Start:       &calendar.EventDateTime{DateTime: "2026-10-19T10:00:00Z"},
+		End:         &calendar.EventDateTime{DateTime: "2026-10-19T11:00:00Z"},
+		Organizer:   &calendar.EventOrganizer{Email: "alice@example.com", DisplayName: "Alice"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "alice@example.com", DisplayName: "Alice", Organizer: true, ResponseStatus: "accepted"},
+			{Email: "me@example.com", Self: true, Optional: true, ResponseStatus: "tentative"},
+		},
+		Attachments: []*calendar.EventAttachment{
+			{Title: "Spec", FileUrl: "https://drive.google.com/file/d/1", MimeType: "application/vnd.google-apps.document"},
+		},
+		ConferenceData: &calendar.ConferenceData{
+			EntryPoints: []*calendar.EntryPoint{
+				{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij"},
+				{EntryPointType: "phone", Uri: "tel:+1-555-0100", Label: "+1 555-0100", Pin: "123456"},
+			},
+		},
+	}
+
+	got, ok := convertEvent(item, "primary", "Work", time.UTC)
+	if !ok {
+		t.Fatal("event was skipped")
+	}
+
+	want := Event{
+		ID:             "abc",
+		Title:          "Design review",
+		StartTime:      time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 10, 19, 11, 0, 0, 0, time.UTC),
+		Provider:       ProviderMeet,
+		JoinURL:        "https://meet.google.com/abc-defg-hij",
+		ResponseStatus: "tentative",
+		CalendarID:     "primary",
+		CalendarName:   "Work",
+		Organizer:      Person{Email: "alice@example.com", Name: "Alice"},
+		Attendees: []Attendee{
+			{Person: Person{Email: "alice@example.com", Name: "Alice"}, ResponseStatus: "accepted", Organizer: true},
+			{Person: Person{Email: "me@example.com", Self: true}, ResponseStatus: "tentative", Optional: true},
+		},
+		Optional:     true,
+		Description:  "Agenda attached",
+		Location:     "Room 1",
+		HTMLLink:     "https://www.google.com/calendar/event?eid=abc",
+		ColorID:      "5",
+		EventType:    "default",
+		Transparency: "opaque",
+		Attachments:  []Attachment{{Title: "Spec", URL: "https://drive.google.com/file/d/1", MimeType: "application/vnd.google-apps.document"}},
+		DialIns:      []DialIn{{Type: "phone", URI: "tel:+1-555-0100", Label: "+1 555-0100", PIN: "123456"}},
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+}
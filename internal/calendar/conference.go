@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+type ConferenceProvider string
+
+const (
+	ProviderMeet  ConferenceProvider = "meet"
+	ProviderZoom  ConferenceProvider = "zoom"
+	ProviderTeams ConferenceProvider = "teams"
+	ProviderWebex ConferenceProvider = "webex"
+)
+
+func (p ConferenceProvider) DisplayName() string {
+	switch p {
+	case ProviderMeet:
+		return "Google Meet"
+	case ProviderZoom:
+		return "Zoom"
+	case ProviderTeams:
+		return "Microsoft Teams"
+	case ProviderWebex:
+		return "Webex"
+	default:
+		return "Meeting"
+	}
+}
+
+var urlPattern = regexp.MustCompile(`https://[^\s"'<>()\[\]]+`)
+
+// extractConference finds the join URL of an event.
+// It looks at hangoutLink, conferenceData entry points, location and description in that order.
+func extractConference(item *calendar.Event) (ConferenceProvider, string) {
+	if item.HangoutLink != "" {
+		return ProviderMeet, item.HangoutLink
+	}
+
+	if item.ConferenceData != nil {
+		for _, entryPoint := range item.ConferenceData.EntryPoints {
+			if entryPoint.EntryPointType != "video" {
+				continue
+			}
+			if provider, ok := detectProvider(entryPoint.Uri); ok {
+				return provider, entryPoint.Uri
+			}
+		}
+	}
+
+	return findConferenceLink(item.Location, item.Description)
+}
+
+// findConferenceLink returns the first recognized conference URL found in the given texts.
+func findConferenceLink(texts ...string) (ConferenceProvider, string) {
+	for _, text := range texts {
+		// Descriptions are often HTML, so URLs may contain escaped entities like &amp;
+		text = html.UnescapeString(text)
+		for _, link := range urlPattern.FindAllString(text, -1) {
+			link = strings.TrimRight(link, ".,;:!?")
+			if provider, ok := detectProvider(link); ok {
+				return provider, link
+			}
+		}
+	}
+	return "", ""
+}
+
+func detectProvider(link string) (ConferenceProvider, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "https" {
+		return "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "meet.google.com":
+		return ProviderMeet, true
+	case hasDomain(host, "zoom.us") || hasDomain(host, "zoomgov.com"):
+		if strings.HasPrefix(u.Path, "/j/") || strings.HasPrefix(u.Path, "/my/") || strings.HasPrefix(u.Path, "/w/") {
+			return ProviderZoom, true
+		}
+	case host == "teams.microsoft.com" || host == "teams.live.com":
+		if strings.HasPrefix(u.Path, "/l/meetup-join/") || strings.HasPrefix(u.Path, "/meet/") {
+			return ProviderTeams, true
+		}
+	case hasDomain(host, "webex.com"):
+		if strings.Contains(u.Path, "/j.php") || strings.HasPrefix(u.Path, "/meet/") || strings.Contains(u.Path, "/join/") {
+			return ProviderWebex, true
+		}
+	}
+	return "", false
+}
+
+func hasDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestExtractConference(t *testing.T) {
+	tests := []struct {
+		name         string
+		item         *calendar.Event
+		wantProvider ConferenceProvider
+		wantURL      string
+	}{
+		{
+			name:         "hangout link",
+			item:         &calendar.Event{HangoutLink: "https://meet.google.com/abc-defg-hij"},
+			wantProvider: ProviderMeet,
+			wantURL:      "https://meet.google.com/abc-defg-hij",
+		},
+		{
+			name: "zoom entry point",
+			item: &calendar.Event{
+				ConferenceData: &calendar.ConferenceData{
+					EntryPoints: []*calendar.EntryPoint{
+						{EntryPointType: "phone", Uri: "tel:+1-555-0100"},
+						{EntryPointType: "video", Uri: "https://example.zoom.us/j/123456789?pwd=secret"},
+					},
+				},
+			},
+			wantProvider: ProviderZoom,
+			wantURL:      "https://example.zoom.us/j/123456789?pwd=secret",
+		},
+		{
+			name:         "teams link in location",
+			item:         &calendar.Event{Location: "https://teams.microsoft.com/l/meetup-join/19%3ameeting_abc%40thread.v2/0"},
+			wantProvider: ProviderTeams,
+			wantURL:      "https://teams.microsoft.com/l/meetup-join/19%3ameeting_abc%40thread.v2/0",
+		},
+		{
+			name: "webex link in html description",
+			item: &calendar.Event{
+				Location:    "Room 4F",
+				Description: `Join: <a href="https://acme.webex.com/acme/j.php?MTID=m123&amp;x=1">https://acme.webex.com/acme/j.php?MTID=m123&amp;x=1</a>.`,
+			},
+			wantProvider: ProviderWebex,
+			wantURL:      "https://acme.webex.com/acme/j.php?MTID=m123&x=1",
+		},
+		{
+			name:         "zoom link in plain description with trailing period",
+			item:         &calendar.Event{Description: "Please join https://zoom.us/j/987654321."},
+			wantProvider: ProviderZoom,
+			wantURL:      "https://zoom.us/j/987654321",
+		},
+		{
+			name:         "unrelated links are ignored",
+			item:         &calendar.Event{Description: "Agenda: https://docs.google.com/document/d/123 and https://zoom.us/pricing"},
+			wantProvider: "",
+			wantURL:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotProvider, gotURL := extractConference(tt.item)
+			if diff := cmp.Diff(tt.wantProvider, gotProvider); diff != "" {
+				t.Errorf("provider mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantURL, gotURL); diff != "" {
+				t.Errorf("URL mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package calendar
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultIterations is the PBKDF2-HMAC-SHA256 work factor recommended by OWASP.
+const defaultIterations = 600_000
+
+// EncryptedFileStore stores tokens as <key>.json.enc files encrypted with
+// AES-256-GCM under a key derived from a passphrase or the contents of a key file.
+// It is meant for headless machines without an OS keyring.
+type EncryptedFileStore struct {
+	Dir        string
+	secret     []byte
+	iterations int
+}
+
+// encryptedFile is the on-disk format of an encrypted token.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// NewEncryptedFileStore returns a store in dir encrypted with secret.
+func NewEncryptedFileStore(dir string, secret []byte) (*EncryptedFileStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("encrypted token store needs a passphrase or key file")
+	}
+	return &EncryptedFileStore{Dir: dir, secret: secret, iterations: defaultIterations}, nil
+}
+
+func (s *EncryptedFileStore) path(key string) string {
+	return filepath.Join(s.Dir, key+".json.enc")
+}
+
+func (s *EncryptedFileStore) Load(key string) (*oauth2.Token, error) {
+	raw, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode token file: %w", err)
+	}
+	if file.Version != 1 {
+		return nil, fmt.Errorf("unsupported token file version %d", file.Version)
+	}
+
+	aead, err := s.cipher(file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, file.Nonce, file.Data, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token, is the passphrase or key file correct?: %w", err)
+	}
+	return decodeToken(data)
+}
+
+func (s *EncryptedFileStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	file := encryptedFile{
+		Version:    1,
+		Iterations: s.iterations,
+		Salt:       make([]byte, 16),
+	}
+	rand.Read(file.Salt)
+
+	aead, err := s.cipher(file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	rand.Read(file.Nonce)
+	// The key is authenticated so a token file cannot be swapped for another account's
+	file.Data = aead.Seal(nil, file.Nonce, data, []byte(key))
+
+	raw, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode token file: %w", err)
+	}
+	return writeFileAtomic(s.path(key), raw)
+}
+
+func (s *EncryptedFileStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete token file: %w", err)
+	}
+	return nil
+}
+
+func (s *EncryptedFileStore) cipher(salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(s.secret), salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
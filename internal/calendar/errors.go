@@ -0,0 +1,181 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrorKind classifies a failure to fetch events so callers can react to it.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	// KindAuthRevoked means access was revoked or lacks permission; the user must authenticate again
+	KindAuthRevoked
+	// KindTokenExpired means the credentials were rejected and could not be refreshed
+	KindTokenExpired
+	// KindRateLimited means the API quota or rate limit was exceeded
+	KindRateLimited
+	// KindServer is a temporary server-side failure
+	KindServer
+	// KindOffline means the server could not be reached
+	KindOffline
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindAuthRevoked:
+		return "auth revoked"
+	case KindTokenExpired:
+		return "token expired"
+	case KindRateLimited:
+		return "rate limited"
+	case KindServer:
+		return "server error"
+	case KindOffline:
+		return "offline"
+	default:
+		return "unknown"
+	}
+}
+
+// Retryable reports whether the request may succeed if retried later without user action.
+func (k ErrorKind) Retryable() bool {
+	return k == KindRateLimited || k == KindServer || k == KindOffline
+}
+
+// HTTPError is an unexpected HTTP response from a non-Google source.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (e *HTTPError) Error() string {
+	return "unexpected status " + e.Status
+}
+
+func newHTTPError(resp *http.Response) *HTTPError {
+	return &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header}
+}
+
+// SourceError is a GetEventsInRange error of a named calendar source.
+// AuthCommand fixes an auth failure of the source; it is empty for sources
+// whose access ooi does not manage, such as ICS subscriptions.
+type SourceError struct {
+	Source      string
+	AuthCommand string
+	Err         error
+}
+
+func (e *SourceError) Error() string {
+	return e.Source + ": " + e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// rateLimitReasons are the 403 reasons Google uses for quota errors rather than permission errors.
+var rateLimitReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+}
+
+// Classify returns the kind of a GetEventsInRange error.
+func Classify(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	if errors.Is(err, ErrLoggedOut) {
+		return KindTokenExpired
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		// The token refresh itself failed
+		if retrieveErr.ErrorCode == "invalid_grant" {
+			return KindAuthRevoked
+		}
+		if retrieveErr.Response != nil && retrieveErr.Response.StatusCode >= 500 {
+			return KindServer
+		}
+		return KindTokenExpired
+	}
+
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) {
+		if gErr.Code == http.StatusForbidden {
+			for _, item := range gErr.Errors {
+				if rateLimitReasons[item.Reason] {
+					return KindRateLimited
+				}
+			}
+		}
+		return classifyStatus(gErr.Code)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return classifyStatus(httpErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return KindOffline
+	}
+
+	return KindUnknown
+}
+
+func classifyStatus(code int) ErrorKind {
+	switch {
+	case code == http.StatusUnauthorized:
+		return KindTokenExpired
+	case code == http.StatusForbidden:
+		return KindAuthRevoked
+	case code == http.StatusTooManyRequests:
+		return KindRateLimited
+	case code >= 500:
+		return KindServer
+	default:
+		return KindUnknown
+	}
+}
+
+// RetryAfter returns the delay requested by the server's Retry-After header, or 0.
+func RetryAfter(err error) time.Duration {
+	var header http.Header
+	var gErr *googleapi.Error
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &gErr):
+		header = gErr.Header
+	case errors.As(err, &httpErr):
+		header = httpErr.Header
+	}
+	return parseRetryAfter(header.Get("Retry-After"), time.Now())
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+func googleError(code int, reason string) string {
+	return fmt.Sprintf(`{"error":{"code":%d,"message":"error","errors":[{"reason":%q,"message":"error"}]}}`, code, reason)
+}
+
+func TestClassifyGoogleErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		retryAfter     string
+		wantKind       ErrorKind
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			body:     googleError(401, "authError"),
+			wantKind: KindTokenExpired,
+		},
+		{
+			name:     "insufficient permissions",
+			status:   http.StatusForbidden,
+			body:     googleError(403, "insufficientPermissions"),
+			wantKind: KindAuthRevoked,
+		},
+		{
+			name:     "403 rate limit is not an auth error",
+			status:   http.StatusForbidden,
+			body:     googleError(403, "rateLimitExceeded"),
+			wantKind: KindRateLimited,
+		},
+		{
+			name:     "quota exceeded",
+			status:   http.StatusForbidden,
+			body:     googleError(403, "quotaExceeded"),
+			wantKind: KindRateLimited,
+		},
+		{
+			name:           "too many requests with Retry-After",
+			status:         http.StatusTooManyRequests,
+			body:           googleError(429, "rateLimitExceeded"),
+			retryAfter:     "30",
+			wantKind:       KindRateLimited,
+			wantRetryAfter: 30 * time.Second,
+		},
+		{
+			name:     "backend error",
+			status:   http.StatusServiceUnavailable,
+			body:     googleError(503, "backendError"),
+			wantKind: KindServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			client := newTestClient(t, api, nil)
+			_, err := client.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if diff := cmp.Diff(tt.wantKind, Classify(err)); diff != "" {
+				t.Errorf("kind mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRetryAfter, RetryAfter(err)); diff != "" {
+				t.Errorf("retry after mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClassifyRevokedRefreshToken(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`)
+	}))
+	defer tokenServer.Close()
+
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)}
+	ctx := context.Background()
+
+	service, err := calendar.NewService(ctx,
+		option.WithEndpoint(tokenServer.URL+"/"),
+		option.WithHTTPClient(config.Client(ctx, expired)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	_, err = newClient(service, nil).GetEventsInRange(ctx, time.Hour, time.Hour)
+	if diff := cmp.Diff(KindAuthRevoked, Classify(err)); diff != "" {
+		t.Errorf("kind mismatch (-want +got):\n%s (%v)", diff, err)
+	}
+}
+
+func TestClassifyOffline(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	source := NewGraphSource(http.DefaultClient, GraphOptions{BaseURL: server.URL})
+	_, err := source.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+
+	if diff := cmp.Diff(KindOffline, Classify(err)); diff != "" {
+		t.Errorf("kind mismatch (-want +got):\n%s (%v)", diff, err)
+	}
+	if !Classify(err).Retryable() {
+		t.Error("offline errors should be retryable")
+	}
+}
+
+func TestClassifyHTTPSourceErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantKind ErrorKind
+	}{
+		{status: http.StatusUnauthorized, wantKind: KindTokenExpired},
+		{status: http.StatusForbidden, wantKind: KindAuthRevoked},
+		{status: http.StatusTooManyRequests, wantKind: KindRateLimited},
+		{status: http.StatusBadGateway, wantKind: KindServer},
+		{status: http.StatusNotFound, wantKind: KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "Wed, 21 Oct 2099 07:28:00 GMT")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			source := NewGraphSource(server.Client(), GraphOptions{BaseURL: server.URL})
+			_, err := source.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+
+			if diff := cmp.Diff(tt.wantKind, Classify(err)); diff != "" {
+				t.Errorf("kind mismatch (-want +got):\n%s (%v)", diff, err)
+			}
+			if RetryAfter(err) <= 0 {
+				t.Error("expected Retry-After as an HTTP date to be honored")
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultGraphBaseURL = "https://graph.microsoft.com/v1.0"
+
+	// graphSyncMargin extends the synced window beyond the requested lookahead
+	// so that delta queries keep covering the rolling window for a while.
+	graphSyncMargin = 24 * time.Hour
+)
+
+var errGraphDeltaExpired = errors.New("delta token expired")
+
+// GraphSource reads events from Microsoft 365 / Outlook via the Microsoft Graph
+// calendarView delta API. After the first full sync only changes are downloaded.
+type GraphSource struct {
+	baseURL    string
+	name       string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	deltaLink string
+	syncStart time.Time
+	syncEnd   time.Time
+	events    map[string]graphEvent
+}
+
+type GraphOptions struct {
+	// BaseURL defaults to DefaultGraphBaseURL.
+	BaseURL string
+	// Name is shown as the calendar name.
+	Name string
+}
+
+// NewGraphSource creates a source using an HTTP client that authorizes requests,
+// e.g. one returned by oauth2.Config.Client.
+func NewGraphSource(httpClient *http.Client, opts GraphOptions) *GraphSource {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGraphBaseURL
+	}
+	name := opts.Name
+	if name == "" {
+		name = "Outlook"
+	}
+	return &GraphSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		name:       name,
+		httpClient: httpClient,
+	}
+}
+
+type graphDateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type graphEmailAddress struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type graphEvent struct {
+	ID          string        `json:"id"`
+	ICalUID     string        `json:"iCalUId"`
+	Subject     string        `json:"subject"`
+	Start       graphDateTime `json:"start"`
+	End         graphDateTime `json:"end"`
+	IsAllDay    bool          `json:"isAllDay"`
+	IsCancelled bool          `json:"isCancelled"`
+	BodyPreview string        `json:"bodyPreview"`
+	Location    struct {
+		DisplayName string `json:"displayName"`
+	} `json:"location"`
+	WebLink       string `json:"webLink"`
+	OnlineMeeting *struct {
+		JoinURL         string   `json:"joinUrl"`
+		ConferenceID    string   `json:"conferenceId"`
+		TollNumber      string   `json:"tollNumber"`
+		TollFreeNumbers []string `json:"tollFreeNumbers"`
+	} `json:"onlineMeeting"`
+	OnlineMeetingURL string `json:"onlineMeetingUrl"`
+	ResponseStatus   struct {
+		Response string `json:"response"`
+	} `json:"responseStatus"`
+	Organizer struct {
+		EmailAddress graphEmailAddress `json:"emailAddress"`
+	} `json:"organizer"`
+	IsOrganizer bool `json:"isOrganizer"`
+	Attendees   []struct {
+		Type         string            `json:"type"` // required, optional, resource
+		EmailAddress graphEmailAddress `json:"emailAddress"`
+		Status       struct {
+			Response string `json:"response"`
+		} `json:"status"`
+	} `json:"attendees"`
+	ShowAs                     string `json:"showAs"`
+	IsReminderOn               bool   `json:"isReminderOn"`
+	ReminderMinutesBeforeStart int    `json:"reminderMinutesBeforeStart"`
+	Removed                    *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed"`
+}
+
+type graphPage struct {
+	Value     []graphEvent `json:"value"`
+	NextLink  string       `json:"@odata.nextLink"`
+	DeltaLink string       `json:"@odata.deltaLink"`
+}
+
+func (s *GraphSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
+	now := time.Now()
+	start := now.Add(-lookback)
+	end := now.Add(lookahead)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.syncWindow(ctx, start, end); err != nil {
+		return nil, &SourceError{Source: s.name, AuthCommand: "ooi auth microsoft", Err: err}
+	}
+
+	var result []Event
+	for _, item := range s.events {
+		event, ok := convertGraphEvent(item)
+		if !ok {
+			continue
+		}
+		if !event.StartTime.Before(end) || !event.EndTime.After(start) {
+			continue
+		}
+		event.CalendarID = s.name
+		event.CalendarName = s.name
+		result = append(result, event)
+	}
+
+	return mergeEvents(result), nil
+}
+
+// OutOfOffice returns events shown as "away" (showAs oof).
+func (s *GraphSource) OutOfOffice(start, end time.Time) []Period {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Period
+	for _, item := range s.events {
+		if item.IsCancelled || item.ShowAs != "oof" {
+			continue
+		}
+		startTime, err := parseGraphDateTime(item.Start)
+		if err != nil {
+			continue
+		}
+		endTime, err := parseGraphDateTime(item.End)
+		if err != nil {
+			continue
+		}
+		if item.IsAllDay {
+			startTime = localDate(startTime)
+			endTime = localDate(endTime)
+		}
+		if !startTime.Before(end) || !endTime.After(start) {
+			continue
+		}
+		result = append(result, Period{Title: item.Subject, Start: startTime, End: endTime})
+	}
+	return result
+}
+
+// syncWindow brings the cached events up to date for [start, end], using the
+// delta link when the window is already covered and a full sync otherwise.
+func (s *GraphSource) syncWindow(ctx context.Context, start, end time.Time) error {
+	if s.deltaLink != "" && !start.Before(s.syncStart) && !end.After(s.syncEnd) {
+		err := s.sync(ctx, s.deltaLink)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errGraphDeltaExpired) {
+			return err
+		}
+	}
+
+	s.syncStart = start
+	s.syncEnd = end.Add(graphSyncMargin)
+	s.events = make(map[string]graphEvent)
+
+	q := url.Values{}
+	q.Set("startDateTime", s.syncStart.UTC().Format(time.RFC3339))
+	q.Set("endDateTime", s.syncEnd.UTC().Format(time.RFC3339))
+	return s.sync(ctx, s.baseURL+"/me/calendarView/delta?"+q.Encode())
+}
+
+// sync follows nextLinks until a deltaLink is returned, applying changes to the cache.
+func (s *GraphSource) sync(ctx context.Context, link string) error {
+	// Force a full sync next time if this one does not complete
+	s.deltaLink = ""
+
+	for link != "" {
+		page, err := s.getPage(ctx, link)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Value {
+			if item.Removed != nil {
+				delete(s.events, item.ID)
+				continue
+			}
+			s.events[item.ID] = item
+		}
+
+		if page.DeltaLink != "" {
+			s.deltaLink = page.DeltaLink
+			return nil
+		}
+		link = page.NextLink
+	}
+
+	return fmt.Errorf("failed to sync events: response had neither nextLink nor deltaLink")
+}
+
+func (s *GraphSource) getPage(ctx context.Context, link string) (*graphPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Prefer", `outlook.timezone="UTC", odata.maxpagesize=50`)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return nil, errGraphDeltaExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch events: %w", newHTTPError(resp))
+	}
+
+	var page graphPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode events: %w", err)
+	}
+	return &page, nil
+}
+
+// convertGraphEvent maps a Graph event to an Event.
+// It returns false for cancelled or declined events and events without a conference link.
+func convertGraphEvent(item graphEvent) (Event, bool) {
+	if item.IsCancelled {
+		return Event{}, false
+	}
+
+	var provider ConferenceProvider
+	var joinURL string
+	if item.OnlineMeeting != nil && item.OnlineMeeting.JoinURL != "" {
+		provider, joinURL = findConferenceLink(item.OnlineMeeting.JoinURL)
+	}
+	if joinURL == "" {
+		provider, joinURL = findConferenceLink(item.OnlineMeetingURL, item.Location.DisplayName, item.BodyPreview)
+	}
+	if joinURL == "" {
+		return Event{}, false
+	}
+
+	responseStatus := graphResponseStatus(item.ResponseStatus.Response)
+	if responseStatus == "declined" {
+		return Event{}, false
+	}
+
+	startTime, err := parseGraphDateTime(item.Start)
+	if err != nil {
+		return Event{}, false
+	}
+	endTime, err := parseGraphDateTime(item.End)
+	if err != nil {
+		return Event{}, false
+	}
+	if item.IsAllDay {
+		// All-day events are returned as midnight UTC; they are dates in the local time zone
+		startTime = localDate(startTime)
+		endTime = localDate(endTime)
+	}
+
+	var reminders []time.Duration
+	if item.IsReminderOn {
+		reminders = []time.Duration{time.Duration(item.ReminderMinutesBeforeStart) * time.Minute}
+	}
+
+	return Event{
+		ID:             item.ID,
+		ICalUID:        item.ICalUID,
+		Title:          item.Subject,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Provider:       provider,
+		JoinURL:        joinURL,
+		ResponseStatus: responseStatus,
+		Reminders:      reminders,
+		AllDay:         item.IsAllDay,
+		Organizer: Person{
+			Email: item.Organizer.EmailAddress.Address,
+			Name:  item.Organizer.EmailAddress.Name,
+			Self:  item.IsOrganizer,
+		},
+		Attendees:    graphAttendees(item),
+		Description:  item.BodyPreview,
+		Location:     item.Location.DisplayName,
+		HTMLLink:     item.WebLink,
+		EventType:    "default",
+		Transparency: graphTransparency(item.ShowAs),
+		DialIns:      graphDialIns(item),
+	}, true
+}
+
+func graphAttendees(item graphEvent) []Attendee {
+	var result []Attendee
+	for _, a := range item.Attendees {
+		result = append(result, Attendee{
+			Person:         Person{Email: a.EmailAddress.Address, Name: a.EmailAddress.Name},
+			ResponseStatus: graphResponseStatus(a.Status.Response),
+			Optional:       a.Type == "optional",
+			Organizer:      strings.EqualFold(a.EmailAddress.Address, item.Organizer.EmailAddress.Address),
+		})
+	}
+	return result
+}
+
+func graphDialIns(item graphEvent) []DialIn {
+	if item.OnlineMeeting == nil {
+		return nil
+	}
+	var result []DialIn
+	for _, number := range append([]string{item.OnlineMeeting.TollNumber}, item.OnlineMeeting.TollFreeNumbers...) {
+		if number == "" {
+			continue
+		}
+		result = append(result, DialIn{Type: "phone", URI: "tel:" + number, Label: number, PIN: item.OnlineMeeting.ConferenceID})
+	}
+	return result
+}
+
+func localDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func graphTransparency(showAs string) string {
+	if showAs == "free" {
+		return "transparent"
+	}
+	return "opaque"
+}
+
+func graphResponseStatus(response string) string {
+	switch response {
+	case "organizer", "accepted":
+		return "accepted"
+	case "tentativelyAccepted":
+		return "tentative"
+	case "declined":
+		return "declined"
+	default:
+		return "needsAction"
+	}
+}
+
+func parseGraphDateTime(dt graphDateTime) (time.Time, error) {
+	loc := time.UTC
+	if dt.TimeZone != "" && dt.TimeZone != "UTC" {
+		l, err := time.LoadLocation(dt.TimeZone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", dt.TimeZone)
+		}
+		loc = l
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05.9999999", dt.DateTime, loc)
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// GraphOAuthConfig returns the OAuth config for a public client app registered in Microsoft Entra ID.
+// tenant may be "common", "organizations", "consumers" or a tenant ID.
+func GraphOAuthConfig(clientID, tenant string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: clientID,
+		Endpoint: microsoft.AzureADEndpoint(tenant),
+		Scopes:   []string{"offline_access", "Calendars.Read"},
+	}
+}
+
+// AuthenticateGraph runs the OAuth device code flow: the user opens the verification URL
+// on any device and enters the code, so no local callback server is needed.
+func AuthenticateGraph(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	resp, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	fmt.Printf("To sign in, open %s and enter the code %s\n", resp.VerificationURI, resp.UserCode)
+	if err := openBrowser(resp.VerificationURI); err != nil {
+		fmt.Println("(Could not open the browser automatically.)")
+	}
+
+	token, err := config.DeviceAccessToken(ctx, resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	return token, nil
+}
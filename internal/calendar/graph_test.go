@@ -0,0 +1,135 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func graphTestEvent(id, subject string, start time.Time, joinURL, response string) map[string]any {
+	const layout = "2006-01-02T15:04:05.0000000"
+	return map[string]any{
+		"id":                         id,
+		"iCalUId":                    "uid-" + id,
+		"subject":                    subject,
+		"start":                      map[string]string{"dateTime": start.UTC().Format(layout), "timeZone": "UTC"},
+		"end":                        map[string]string{"dateTime": start.Add(30 * time.Minute).UTC().Format(layout), "timeZone": "UTC"},
+		"onlineMeeting":              map[string]string{"joinUrl": joinURL},
+		"responseStatus":             map[string]string{"response": response},
+		"isReminderOn":               true,
+		"reminderMinutesBeforeStart": 5,
+	}
+}
+
+func TestGraphSourceDeltaSync(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	teams := "https://teams.microsoft.com/l/meetup-join/abc"
+
+	var deltaCalls int
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v1.0/me/calendarView/delta", func(w http.ResponseWriter, r *http.Request) {
+		var page map[string]any
+		switch {
+		case r.URL.Query().Get("page") == "2":
+			page = map[string]any{
+				"value":            []any{graphTestEvent("b", "Review", start, teams, "tentativelyAccepted")},
+				"@odata.deltaLink": server.URL + "/v1.0/me/calendarView/delta?deltatoken=1",
+			}
+		case r.URL.Query().Get("deltatoken") == "1":
+			deltaCalls++
+			page = map[string]any{
+				"value": []any{
+					map[string]any{"id": "a", "@removed": map[string]string{"reason": "deleted"}},
+					graphTestEvent("c", "Declined", start, teams, "declined"),
+				},
+				"@odata.deltaLink": server.URL + "/v1.0/me/calendarView/delta?deltatoken=1",
+			}
+		default:
+			if r.URL.Query().Get("startDateTime") == "" || r.URL.Query().Get("endDateTime") == "" {
+				http.Error(w, "missing window", http.StatusBadRequest)
+				return
+			}
+			page = map[string]any{
+				"value":           []any{graphTestEvent("a", "Standup", start, teams, "organizer")},
+				"@odata.nextLink": server.URL + "/v1.0/me/calendarView/delta?page=2",
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	source := NewGraphSource(server.Client(), GraphOptions{BaseURL: server.URL + "/v1.0"})
+
+	summarize := func(events []Event) []string {
+		var result []string
+		for _, e := range events {
+			result = append(result, fmt.Sprintf("%s/%s/%s/%v", e.Title, e.ResponseStatus, e.Provider, e.Reminders))
+		}
+		return result
+	}
+
+	got, err := source.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("initial sync failed: %v", err)
+	}
+	want := []string{"Standup/accepted/teams/[5m0s]", "Review/tentative/teams/[5m0s]"}
+	if diff := cmp.Diff(want, summarize(got)); diff != "" {
+		t.Errorf("initial events mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = source.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("delta sync failed: %v", err)
+	}
+	want = []string{"Review/tentative/teams/[5m0s]"}
+	if diff := cmp.Diff(want, summarize(got)); diff != "" {
+		t.Errorf("delta events mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1, deltaCalls); diff != "" {
+		t.Errorf("delta call count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGraphSourceDeltaExpired(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	var fullSyncs int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("deltatoken") != "" {
+			http.Error(w, "syncStateNotFound", http.StatusGone)
+			return
+		}
+		fullSyncs++
+		json.NewEncoder(w).Encode(map[string]any{
+			"value":            []any{graphTestEvent("a", "Standup", start, "https://zoom.us/j/1", "accepted")},
+			"@odata.deltaLink": server.URL + "/me/calendarView/delta?deltatoken=1",
+		})
+	}))
+	defer server.Close()
+
+	source := NewGraphSource(server.Client(), GraphOptions{BaseURL: server.URL})
+	for range 2 {
+		got, err := source.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+		if err != nil {
+			t.Fatalf("GetEventsInRange failed: %v", err)
+		}
+		if diff := cmp.Diff(1, len(got)); diff != "" {
+			t.Errorf("event count mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	// The expired delta token falls back to a full sync
+	if diff := cmp.Diff(2, fullSyncs); diff != "" {
+		t.Errorf("full sync count mismatch (-want +got):\n%s", diff)
+	}
+}
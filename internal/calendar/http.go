@@ -0,0 +1,27 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultHTTPClient is used by sources without an HTTPClient option. Its
+// timeout keeps a server that never answers from blocking a fetch.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxResponseSize caps the calendar data read from a server.
+const maxResponseSize = 32 << 20
+
+// readLimited reads r up to maxResponseSize and fails if there is more.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseSize {
+		return nil, fmt.Errorf("response exceeds %d MiB", maxResponseSize>>20)
+	}
+	return data, nil
+}
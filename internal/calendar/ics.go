@@ -0,0 +1,269 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knwoop/ooi/internal/ical"
+)
+
+// ICSSource reads events from a local .ics file or a published ICS subscription URL.
+type ICSSource struct {
+	location   string
+	name       string
+	email      string
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+type ICSOptions struct {
+	// Name is shown as the calendar name. Defaults to the calendar's X-WR-CALNAME.
+	Name string
+	// Email identifies you among the attendees to read your response status.
+	Email string
+	// HTTPClient is used for subscription URLs. Defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// NewICSSource creates a source for a file path or an http(s)/webcal URL.
+func NewICSSource(location string, opts ICSOptions) *ICSSource {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return &ICSSource{
+		location:   location,
+		name:       opts.Name,
+		email:      opts.Email,
+		httpClient: httpClient,
+	}
+}
+
+func (s *ICSSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
+	data, err := s.read(ctx)
+	if err != nil {
+		source := s.name
+		if source == "" {
+			source = "ICS calendar"
+		}
+		return nil, &SourceError{Source: source, Err: err}
+	}
+
+	cal, err := ical.ParseCalendar(bytes.NewReader(data), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar: %w", err)
+	}
+
+	now := time.Now()
+	calendarName := s.name
+	if calendarName == "" {
+		calendarName = cal.Name
+	}
+
+	var result []Event
+	for _, item := range cal.Expand(now.Add(-lookback), now.Add(lookahead)) {
+		event, ok := convertICalEvent(item, s.email)
+		if !ok {
+			continue
+		}
+		event.CalendarID = s.calendarID()
+		event.CalendarName = calendarName
+		result = append(result, event)
+	}
+
+	return mergeEvents(result), nil
+}
+
+// convertICalEvent maps an expanded VEVENT to an Event.
+// It returns false for events without a conference link or that you declined.
+func convertICalEvent(item ical.Event, email string) (Event, bool) {
+	provider, joinURL := findConferenceLink(item.URL, item.Location, item.Description)
+	if joinURL == "" {
+		return Event{}, false
+	}
+
+	responseStatus := icalResponseStatus(item, email)
+	if responseStatus == "declined" {
+		return Event{}, false
+	}
+
+	return Event{
+		ID:             item.UID + "_" + item.Start.UTC().Format("20060102T150405Z"),
+		ICalUID:        item.UID,
+		Title:          item.Summary,
+		StartTime:      item.Start,
+		EndTime:        item.End,
+		Provider:       provider,
+		JoinURL:        joinURL,
+		ResponseStatus: responseStatus,
+		Reminders:      item.Alarms,
+		AllDay:         item.AllDay,
+		Organizer:      Person{Email: item.Organizer, Name: item.OrganizerName, Self: isSelf(item.Organizer, email)},
+		Attendees:      icalAttendees(item, email),
+		Optional:       icalOptional(item, email),
+		Description:    item.Description,
+		Location:       item.Location,
+		ColorID:        item.Color,
+		EventType:      "default",
+		Transparency:   icalTransparency(item.Transp),
+		Attachments:    icalAttachments(item),
+	}, true
+}
+
+func isSelf(address, email string) bool {
+	return email != "" && strings.EqualFold(address, email)
+}
+
+func icalAttendees(item ical.Event, email string) []Attendee {
+	var result []Attendee
+	for _, a := range item.Attendees {
+		result = append(result, Attendee{
+			Person:         Person{Email: a.Email, Name: a.Name, Self: isSelf(a.Email, email)},
+			ResponseStatus: partStatStatus(a.PartStat),
+			Optional:       a.Role == "OPT-PARTICIPANT",
+			Organizer:      strings.EqualFold(a.Email, item.Organizer),
+		})
+	}
+	return result
+}
+
+func icalAttachments(item ical.Event) []Attachment {
+	var result []Attachment
+	for _, a := range item.Attachments {
+		result = append(result, Attachment{Title: a.Filename, URL: a.URL, MimeType: a.FmtType})
+	}
+	return result
+}
+
+func icalOptional(item ical.Event, email string) bool {
+	if email == "" {
+		return false
+	}
+	for _, attendee := range item.Attendees {
+		if strings.EqualFold(attendee.Email, email) {
+			return attendee.Role == "OPT-PARTICIPANT"
+		}
+	}
+	return false
+}
+
+func icalTransparency(transp string) string {
+	if transp == "TRANSPARENT" {
+		return "transparent"
+	}
+	return "opaque"
+}
+
+func icalResponseStatus(item ical.Event, email string) string {
+	if email == "" || strings.EqualFold(item.Organizer, email) {
+		// Events on your own published calendar are treated as accepted
+		return "accepted"
+	}
+	for _, attendee := range item.Attendees {
+		if strings.EqualFold(attendee.Email, email) {
+			return partStatStatus(attendee.PartStat)
+		}
+	}
+	return "accepted"
+}
+
+func partStatStatus(partStat string) string {
+	switch partStat {
+	case "ACCEPTED":
+		return "accepted"
+	case "TENTATIVE":
+		return "tentative"
+	case "DECLINED":
+		return "declined"
+	default:
+		return "needsAction"
+	}
+}
+
+func (s *ICSSource) calendarID() string {
+	if s.name != "" {
+		return s.name
+	}
+	if u, err := url.Parse(s.location); err == nil && u.Host != "" {
+		// Subscription URLs usually embed a secret, so only expose the host
+		return u.Host
+	}
+	return filepath.Base(s.location)
+}
+
+func (s *ICSSource) read(ctx context.Context) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(s.location, "http://"), strings.HasPrefix(s.location, "https://"):
+		return s.fetch(ctx, s.location)
+	case strings.HasPrefix(s.location, "webcal://"):
+		return s.fetch(ctx, "https://"+strings.TrimPrefix(s.location, "webcal://"))
+	}
+
+	path := s.location
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, rest)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// fetch downloads the subscription, reusing the previous body when the server answers 304 Not Modified.
+func (s *ICSSource) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if s.body != nil {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && s.body != nil:
+		return s.body, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch calendar: %w", newHTTPError(resp))
+	}
+
+	body, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	s.body = body
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	return body, nil
+}
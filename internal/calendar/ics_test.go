@@ -0,0 +1,164 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testICS(start time.Time) string {
+	const layout = "20060102T150405Z"
+	return fmt.Sprintf(`BEGIN:VCALENDAR
+VERSION:2.0
+X-WR-CALNAME:Outlook
+BEGIN:VEVENT
+UID:sync-1
+SUMMARY:Sync
+DTSTART:%[1]s
+DTEND:%[2]s
+LOCATION:https://teams.microsoft.com/l/meetup-join/abc
+ORGANIZER;CN=Alice:mailto:alice@example.com
+ATTENDEE;CN=Alice;PARTSTAT=ACCEPTED:mailto:alice@example.com
+ATTENDEE;PARTSTAT=TENTATIVE;ROLE=OPT-PARTICIPANT:mailto:me@example.com
+ATTACH;FMTTYPE=application/pdf;FILENAME=agenda.pdf:https://example.com/agenda.pdf
+BEGIN:VALARM
+ACTION:DISPLAY
+TRIGGER:-PT10M
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:declined-1
+SUMMARY:Declined
+DTSTART:%[1]s
+DTEND:%[2]s
+LOCATION:https://zoom.us/j/1
+ATTENDEE;PARTSTAT=DECLINED:mailto:me@example.com
+END:VEVENT
+BEGIN:VEVENT
+UID:nolink-1
+SUMMARY:Lunch
+DTSTART:%[1]s
+DTEND:%[2]s
+END:VEVENT
+END:VCALENDAR
+`, start.UTC().Format(layout), start.Add(30*time.Minute).UTC().Format(layout))
+}
+
+func TestICSSourceURL(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, testICS(start))
+	}))
+	defer server.Close()
+
+	source := NewICSSource(server.URL+"/calendar.ics", ICSOptions{Email: "me@example.com"})
+
+	want := []Event{
+		{
+			ID:             "sync-1_" + start.UTC().Format("20060102T150405Z"),
+			ICalUID:        "sync-1",
+			Title:          "Sync",
+			StartTime:      start,
+			EndTime:        start.Add(30 * time.Minute),
+			Provider:       ProviderTeams,
+			JoinURL:        "https://teams.microsoft.com/l/meetup-join/abc",
+			ResponseStatus: "tentative",
+			CalendarID:     server.Listener.Addr().String(),
+			CalendarName:   "Outlook",
+			Reminders:      []time.Duration{10 * time.Minute},
+			Organizer:      Person{Email: "alice@example.com", Name: "Alice"},
+			Attendees: []Attendee{
+				{Person: Person{Email: "alice@example.com", Name: "Alice"}, ResponseStatus: "accepted", Organizer: true},
+				{Person: Person{Email: "me@example.com", Self: true}, ResponseStatus: "tentative", Optional: true},
+			},
+			Optional:     true,
+			Location:     "https://teams.microsoft.com/l/meetup-join/abc",
+			Attachments:  []Attachment{{Title: "agenda.pdf", URL: "https://example.com/agenda.pdf", MimeType: "application/pdf"}},
+			EventType:    "default",
+			Transparency: "opaque",
+		},
+	}
+
+	// The second fetch is answered with 304 Not Modified and reuses the cached body
+	for range 2 {
+		got, err := source.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("GetEventsInRange failed: %v", err)
+		}
+		if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if diff := cmp.Diff(2, requests); diff != "" {
+		t.Errorf("request count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1, notModified); diff != "" {
+		t.Errorf("not modified count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestICSSourceFile(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	path := filepath.Join(t.TempDir(), "work.ics")
+	if err := os.WriteFile(path, []byte(testICS(start)), 0o600); err != nil {
+		t.Fatalf("failed to write ics file: %v", err)
+	}
+
+	source := NewICSSource(path, ICSOptions{Name: "Work"})
+	got, err := source.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+
+	// Without an email, events on your own calendar are treated as accepted
+	var titles []string
+	for _, e := range got {
+		titles = append(titles, e.Title+"/"+e.ResponseStatus+"/"+e.CalendarName)
+	}
+	wantTitles := []string{"Sync/accepted/Work", "Declined/accepted/Work"}
+	if diff := cmp.Diff(wantTitles, titles); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestICSSourceHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	source := NewICSSource(server.URL, ICSOptions{})
+	if _, err := source.GetEventsInRange(context.Background(), time.Hour, time.Hour); err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestICSSourceResponseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("X"), maxResponseSize+1))
+	}))
+	defer server.Close()
+
+	source := NewICSSource(server.URL, ICSOptions{})
+	if _, err := source.GetEventsInRange(context.Background(), time.Hour, time.Hour); err == nil {
+		t.Error("expected error for a response over the size limit")
+	}
+}
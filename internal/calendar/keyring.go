@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// keyringService is the service name tokens are stored under in the OS keyring.
+const keyringService = "ooi"
+
+// runFunc runs a command with stdin and returns its standard output.
+type runFunc func(stdin string, name string, args ...string) ([]byte, error)
+
+func runCommand(stdin string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		cmdErr := &commandError{name: name, err: err, code: -1, stderr: strings.TrimSpace(stderr.String())}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmdErr.code = exitErr.ExitCode()
+		}
+		return out, cmdErr
+	}
+	return out, nil
+}
+
+// commandError is a failed command with its exit status and what it printed to stderr.
+type commandError struct {
+	name string
+	err  error
+	// code is the exit status, or -1 if the command could not be run
+	code   int
+	stderr string
+}
+
+func (e *commandError) Error() string {
+	if e.stderr == "" {
+		return e.name + ": " + e.err.Error()
+	}
+	return e.name + ": " + e.err.Error() + ": " + e.stderr
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+func exitCode(err error) int {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	return -1
+}
+
+// silentFailure reports whether the command exited unsuccessfully without printing an error.
+func silentFailure(err error) bool {
+	var cmdErr *commandError
+	return errors.As(err, &cmdErr) && cmdErr.code > 0 && cmdErr.stderr == ""
+}
+
+// KeychainStore stores tokens in the macOS login keychain using the security command.
+type KeychainStore struct {
+	run runFunc
+}
+
+func NewKeychainStore() *KeychainStore {
+	return &KeychainStore{run: runCommand}
+}
+
+// errSecItemNotFound is the exit status of security when no keychain item matches.
+const errSecItemNotFound = 44
+
+func (s *KeychainStore) Load(key string) (*oauth2.Token, error) {
+	out, err := s.run("", "security", "find-generic-password", "-s", keyringService, "-a", key, "-w")
+	if err != nil {
+		if exitCode(err) == errSecItemNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read token from keychain: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	return decodeToken(data)
+}
+
+func (s *KeychainStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	// Pass the secret on stdin in interactive mode so it never appears in the process list.
+	// Base64 keeps it free of characters that would need quoting.
+	secret := base64.StdEncoding.EncodeToString(data)
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringService, key, secret)
+	if _, err := s.run(command, "security", "-i"); err != nil {
+		return fmt.Errorf("failed to save token to keychain: %w", err)
+	}
+	return nil
+}
+
+func (s *KeychainStore) Delete(key string) error {
+	if _, err := s.run("", "security", "delete-generic-password", "-s", keyringService, "-a", key); err != nil {
+		if exitCode(err) == errSecItemNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to delete token from keychain: %w", err)
+	}
+	return nil
+}
+
+// SecretServiceStore stores tokens with the freedesktop Secret Service
+// (GNOME Keyring, KWallet) using the secret-tool command.
+type SecretServiceStore struct {
+	run runFunc
+}
+
+func NewSecretServiceStore() *SecretServiceStore {
+	return &SecretServiceStore{run: runCommand}
+}
+
+func (s *SecretServiceStore) Load(key string) (*oauth2.Token, error) {
+	out, err := s.run("", "secret-tool", "lookup", "service", keyringService, "account", key)
+	// secret-tool fails without a message when no secret matches
+	if silentFailure(err) || (err == nil && len(bytes.TrimSpace(out)) == 0) {
+		return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from secret service: %w", err)
+	}
+	return decodeToken(out)
+}
+
+func (s *SecretServiceStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	// secret-tool reads the secret from stdin
+	if _, err := s.run(string(data), "secret-tool", "store", "--label="+keyringService+" "+key, "service", keyringService, "account", key); err != nil {
+		return fmt.Errorf("failed to save token to secret service: %w", err)
+	}
+	return nil
+}
+
+func (s *SecretServiceStore) Delete(key string) error {
+	if _, err := s.run("", "secret-tool", "clear", "service", keyringService, "account", key); err != nil && !silentFailure(err) {
+		return fmt.Errorf("failed to delete token from secret service: %w", err)
+	}
+	return nil
+}
+
+func decodeToken(data []byte) (*oauth2.Token, error) {
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	return &token, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package calendar
+
+// lockFile is a no-op where advisory file locks are unavailable.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	googleTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	googleRevokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// TokenInfo describes a Google token.
+type TokenInfo struct {
+	// Email is the account's address, empty if it could not be looked up
+	Email  string
+	Scopes []string
+	// Expiry is when the access token expires; it is refreshed automatically
+	// as long as the refresh token is valid
+	Expiry time.Time
+}
+
+// InspectToken returns the scopes and expiry Google reports for the account's
+// token, refreshing it first if it has expired.
+func InspectToken(ctx context.Context, account string, store TokenStore) (*TokenInfo, error) {
+	config, err := GetOAuthConfig(account)
+	if err != nil {
+		return nil, err
+	}
+	token, err := store.Load(TokenKey(account))
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := PersistentTokenSource(ctx, config, store, TokenKey(account), token).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	info, err := fetchTokenInfo(ctx, http.DefaultClient, googleTokenInfoURL, current.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	info.Expiry = current.Expiry
+
+	client, err := NewClient(ctx, current, nil, ClientOptions{Account: account, Store: store})
+	if err != nil {
+		return nil, err
+	}
+	if err := client.resolveEmail(ctx); err == nil {
+		info.Email = client.email
+	}
+	return info, nil
+}
+
+func fetchTokenInfo(ctx context.Context, client *http.Client, endpoint, accessToken string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+url.Values{"access_token": {accessToken}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get token info: %w", newHTTPError(resp))
+	}
+
+	var body struct {
+		Scope string `json:"scope"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token info: %w", err)
+	}
+	return &TokenInfo{Email: body.Email, Scopes: strings.Fields(body.Scope)}, nil
+}
+
+// ErrTokenAlreadyRevoked is returned by RevokeToken when Google no longer knows the token.
+var ErrTokenAlreadyRevoked = errors.New("token was already revoked or expired")
+
+// RevokeToken revokes the grant behind a Google token, so neither its access
+// token nor its refresh token can be used any more.
+func RevokeToken(ctx context.Context, token *oauth2.Token) error {
+	return revokeToken(ctx, http.DefaultClient, googleRevokeURL, token)
+}
+
+func revokeToken(ctx context.Context, client *http.Client, endpoint string, token *oauth2.Token) error {
+	// Revoking the refresh token also revokes the access tokens issued from it
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+
+	form := url.Values{"token": {value}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "invalid_token") {
+		return ErrTokenAlreadyRevoked
+	}
+	return fmt.Errorf("failed to revoke token: %w", newHTTPError(resp))
+}
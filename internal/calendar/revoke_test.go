@@ -0,0 +1,68 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+func TestFetchTokenInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "access" {
+			http.Error(w, `{"error":"invalid_token"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"azp":"id","aud":"id","scope":"https://www.googleapis.com/auth/calendar.readonly https://www.googleapis.com/auth/calendar.events","exp":"1760000000","expires_in":"3599"}`))
+	}))
+	defer server.Close()
+
+	got, err := fetchTokenInfo(context.Background(), server.Client(), server.URL, "access")
+	if err != nil {
+		t.Fatalf("fetchTokenInfo failed: %v", err)
+	}
+	want := &TokenInfo{Scopes: []string{
+		"https://www.googleapis.com/auth/calendar.readonly",
+		"https://www.googleapis.com/auth/calendar.events",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("token info mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := fetchTokenInfo(context.Background(), server.Client(), server.URL, "revoked"); err == nil {
+		t.Error("expected an error for an invalid token")
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.PostFormValue("token")
+		if token == "gone" {
+			http.Error(w, `{"error":"invalid_token","error_description":"Token expired or revoked"}`, http.StatusBadRequest)
+			return
+		}
+		revoked = append(revoked, token)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if err := revokeToken(ctx, server.Client(), server.URL, &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}); err != nil {
+		t.Fatalf("revokeToken failed: %v", err)
+	}
+	if err := revokeToken(ctx, server.Client(), server.URL, &oauth2.Token{AccessToken: "access"}); err != nil {
+		t.Fatalf("revokeToken failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"refresh", "access"}, revoked); diff != "" {
+		t.Errorf("revoked tokens mismatch (-want +got):\n%s", diff)
+	}
+
+	err := revokeToken(ctx, server.Client(), server.URL, &oauth2.Token{RefreshToken: "gone"})
+	if !errors.Is(err, ErrTokenAlreadyRevoked) {
+		t.Errorf("expected ErrTokenAlreadyRevoked, got %v", err)
+	}
+}
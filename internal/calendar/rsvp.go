@@ -0,0 +1,115 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Responses to an invitation, as used in Event.ResponseStatus.
+const (
+	ResponseAccepted  = "accepted"
+	ResponseDeclined  = "declined"
+	ResponseTentative = "tentative"
+)
+
+var (
+	// ErrRSVPNotSupported is returned for events from sources that cannot respond to invitations.
+	ErrRSVPNotSupported = errors.New("responding is not supported for this calendar")
+	// ErrRSVPScope means the token was granted read-only access.
+	ErrRSVPScope = errors.New("ooi may not respond to invitations; set rsvp = true in config.toml and run 'ooi auth' again")
+)
+
+// RSVPSource is implemented by sources that can respond to invitations.
+type RSVPSource interface {
+	// RSVP sets your response to the event, notifying the organizer.
+	// It returns ErrRSVPNotSupported if the event is not from this source.
+	RSVP(ctx context.Context, event Event, response string) error
+}
+
+// ParseResponse accepts accept, decline and tentative and their variants.
+func ParseResponse(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "accept", "accepted", "yes":
+		return ResponseAccepted, nil
+	case "decline", "declined", "no":
+		return ResponseDeclined, nil
+	case "tentative", "maybe":
+		return ResponseTentative, nil
+	}
+	return "", fmt.Errorf("invalid response %q, use accept, decline or tentative", s)
+}
+
+func (c *Client) RSVP(ctx context.Context, event Event, response string) error {
+	if event.Account != c.account || !slices.Contains(c.calendarIDs, event.CalendarID) {
+		return ErrRSVPNotSupported
+	}
+
+	// The attendee list is replaced as a whole, so start from the current one
+	item, err := c.service.Events.Get(event.CalendarID, event.ID).Fields("attendees").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	found := false
+	for _, attendee := range item.Attendees {
+		if attendee.Self {
+			attendee.ResponseStatus = response
+			found = true
+		}
+	}
+	if !found {
+		return errors.New("you are not a guest of this event")
+	}
+
+	patch := &calendar.Event{Attendees: item.Attendees}
+	_, err = c.service.Events.Patch(event.CalendarID, event.ID, patch).SendUpdates("all").Fields("id").Context(ctx).Do()
+	if err != nil {
+		if insufficientScope(err) {
+			return ErrRSVPScope
+		}
+		return fmt.Errorf("failed to update response: %w", err)
+	}
+	return nil
+}
+
+func insufficientScope(err error) bool {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) || gErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range gErr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			return true
+		}
+	}
+	return strings.Contains(gErr.Message, "insufficient authentication scopes")
+}
+
+func (m *multiSource) RSVP(ctx context.Context, event Event, response string) error {
+	for _, source := range m.sources {
+		rsvp, ok := source.(RSVPSource)
+		if !ok {
+			continue
+		}
+		if err := rsvp.RSVP(ctx, event, response); !errors.Is(err, ErrRSVPNotSupported) {
+			return err
+		}
+	}
+	return ErrRSVPNotSupported
+}
+
+// RSVP responds to the event if source supports it.
+func RSVP(ctx context.Context, source EventSource, event Event, response string) error {
+	rsvp, ok := source.(RSVPSource)
+	if !ok {
+		return ErrRSVPNotSupported
+	}
+	return rsvp.RSVP(ctx, event, response)
+}
@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClientRSVP(t *testing.T) {
+	var patched []map[string]any
+	var sendUpdates string
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/calendars/primary/events/ev1" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]any{"attendees": []map[string]any{
+				{"email": "boss@example.com", "organizer": true, "responseStatus": "accepted"},
+				{"email": "me@example.com", "self": true, "responseStatus": "needsAction"},
+			}})
+		case http.MethodPatch:
+			var body struct {
+				Attendees []map[string]any `json:"attendees"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			patched = body.Attendees
+			sendUpdates = r.URL.Query().Get("sendUpdates")
+			json.NewEncoder(w).Encode(map[string]any{"id": "ev1"})
+		}
+	})
+
+	client := newTestClient(t, api, []string{"primary"})
+	ctx := context.Background()
+
+	event := Event{ID: "ev1", CalendarID: "primary"}
+	if err := client.RSVP(ctx, event, ResponseTentative); err != nil {
+		t.Fatalf("RSVP failed: %v", err)
+	}
+
+	var statuses []string
+	for _, attendee := range patched {
+		statuses = append(statuses, attendee["responseStatus"].(string))
+	}
+	if diff := cmp.Diff([]string{"accepted", "tentative"}, statuses); diff != "" {
+		t.Errorf("patched responses mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("all", sendUpdates); diff != "" {
+		t.Errorf("sendUpdates mismatch (-want +got):\n%s", diff)
+	}
+
+	// Events of other accounts are left to their own client
+	other := Event{ID: "ev1", CalendarID: "primary", Account: "work"}
+	if err := MergeSources(client, &ICSSource{}).(RSVPSource).RSVP(ctx, other, ResponseAccepted); !errors.Is(err, ErrRSVPNotSupported) {
+		t.Errorf("expected ErrRSVPNotSupported, got %v", err)
+	}
+}
+
+func TestClientRSVPInsufficientScope(t *testing.T) {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]any{"attendees": []map[string]any{{"email": "me@example.com", "self": true}}})
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":403,"message":"Request had insufficient authentication scopes.","errors":[{"reason":"insufficientPermissions"}]}}`))
+	})
+
+	client := newTestClient(t, api, []string{"primary"})
+	err := client.RSVP(context.Background(), Event{ID: "ev1", CalendarID: "primary"}, ResponseAccepted)
+	if !errors.Is(err, ErrRSVPScope) {
+		t.Errorf("expected ErrRSVPScope, got %v", err)
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	for input, want := range map[string]string{
+		"accept":    ResponseAccepted,
+		"Declined":  ResponseDeclined,
+		"tentative": ResponseTentative,
+		"maybe":     ResponseTentative,
+	} {
+		got, err := ParseResponse(input)
+		if err != nil {
+			t.Errorf("ParseResponse(%q) failed: %v", input, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ParseResponse(%q) mismatch (-want +got):\n%s", input, diff)
+		}
+	}
+
+	if _, err := ParseResponse("later"); err == nil {
+		t.Error("expected an error")
+	}
+}
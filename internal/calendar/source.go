@@ -0,0 +1,122 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// EventSource provides meeting events from a calendar backend.
+type EventSource interface {
+	// GetEventsInRange returns events overlapping [now-lookback, now+lookahead],
+	// sorted by start time and response status priority.
+	GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error)
+}
+
+// Period is a span of time such as an out-of-office event.
+type Period struct {
+	Title string
+	Start time.Time
+	End   time.Time
+}
+
+// OutOfOfficeSource is implemented by sources that know when you are out of office.
+type OutOfOfficeSource interface {
+	// OutOfOffice returns your out-of-office periods overlapping [start, end]
+	// as of the last GetEventsInRange call.
+	OutOfOffice(start, end time.Time) []Period
+}
+
+type multiSource struct {
+	sources []EventSource
+}
+
+// PartialError is returned with the events of the sources that worked when
+// other sources failed. Err joins the errors of the failed sources.
+type PartialError struct {
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return "some calendars could not be fetched: " + e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// KeepMissingCalendars adds the events of previous whose calendar, identified by
+// account and calendar ID, has no events in events. After a *PartialError this
+// keeps the last known events of the sources that failed.
+func KeepMissingCalendars(events, previous []Event) []Event {
+	type calendarKey struct{ account, calendarID string }
+	fetched := make(map[calendarKey]bool)
+	for _, event := range events {
+		fetched[calendarKey{event.Account, event.CalendarID}] = true
+	}
+
+	result := slices.Clone(events)
+	for _, event := range previous {
+		if !fetched[calendarKey{event.Account, event.CalendarID}] {
+			result = append(result, event)
+		}
+	}
+	return mergeEvents(result)
+}
+
+// MergeSources combines several sources into one. Events are merged and
+// de-duplicated the same way as events from multiple Google calendars.
+// When some sources fail, the events of the others are returned with a
+// *PartialError; the fetch only fails when every source fails.
+func MergeSources(sources ...EventSource) EventSource {
+	if len(sources) == 1 {
+		return sources[0]
+	}
+	return &multiSource{sources: sources}
+}
+
+func (m *multiSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
+	var result []Event
+	var errs []error
+	var failedSources int
+	for _, source := range m.sources {
+		events, err := source.GetEventsInRange(ctx, lookback, lookahead)
+		var partial *PartialError
+		switch {
+		case errors.As(err, &partial):
+			errs = append(errs, unwrapJoined(partial.Err)...)
+		case err != nil:
+			errs = append(errs, unwrapJoined(err)...)
+			failedSources++
+			continue
+		}
+		result = append(result, events...)
+	}
+
+	switch {
+	case failedSources == len(m.sources):
+		return nil, errors.Join(errs...)
+	case len(errs) > 0:
+		return mergeEvents(result), &PartialError{Err: errors.Join(errs...)}
+	}
+	return mergeEvents(result), nil
+}
+
+// unwrapJoined returns the errors joined in err, or err itself.
+func unwrapJoined(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func (m *multiSource) OutOfOffice(start, end time.Time) []Period {
+	var result []Period
+	for _, source := range m.sources {
+		if oof, ok := source.(OutOfOfficeSource); ok {
+			result = append(result, oof.OutOfOffice(start, end)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type stubSource struct {
+	events []Event
+	err    error
+}
+
+func (s stubSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]Event, error) {
+	return s.events, s.err
+}
+
+func TestMultiSourcePartialFailure(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	ok := stubSource{events: []Event{{ID: "standup", Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute)}}}
+	unreachable := stubSource{err: errors.New("dial tcp: connection refused")}
+	forbidden := stubSource{err: &HTTPError{StatusCode: 403, Status: "403 Forbidden"}}
+
+	// Events of the sources that worked are returned with the errors of the others
+	events, err := MergeSources(ok, unreachable, forbidden).GetEventsInRange(context.Background(), 0, 2*time.Hour)
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialError, got %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Errorf("expected the HTTPError to be kept, got %v", err)
+	}
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if diff := cmp.Diff([]string{"standup"}, ids); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	// A source that is itself partial keeps its events
+	partialSource := stubSource{events: ok.events, err: &PartialError{Err: errors.Join(forbidden.err)}}
+	events, err = MergeSources(partialSource, stubSource{}).GetEventsInRange(context.Background(), 0, 2*time.Hour)
+	if !errors.As(err, &partial) || len(events) != 1 {
+		t.Errorf("expected a PartialError with 1 event, got %d events and %v", len(events), err)
+	}
+
+	// The fetch fails when every source fails
+	events, err = MergeSources(unreachable, forbidden).GetEventsInRange(context.Background(), 0, 2*time.Hour)
+	if err == nil || errors.As(err, &partial) {
+		t.Errorf("expected a full failure, got %v", err)
+	}
+	if events != nil {
+		t.Errorf("expected no events, got %v", events)
+	}
+}
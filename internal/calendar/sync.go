@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// googleSyncMargin extends the synced window beyond the requested lookahead
+// so that incremental syncs keep covering the rolling window for a while.
+const googleSyncMargin = 24 * time.Hour
+
+// eventFields restricts responses to the fields ooi uses.
+const eventFields = "nextPageToken,nextSyncToken,summary,timeZone,defaultReminders(method,minutes)," +
+	"items(id,iCalUID,status,summary,start,end,hangoutLink,htmlLink,location,description,colorId,eventType,transparency,reminders," +
+	"conferenceData/entryPoints(entryPointType,uri,label,pin,accessCode),attachments(title,fileUrl,mimeType)," +
+	"organizer(email,displayName,self),attendees(email,displayName,self,organizer,responseStatus,optional))"
+
+// calendarCache holds the synced events of one calendar.
+type calendarCache struct {
+	name      string
+	location  *time.Location
+	syncToken string
+	syncStart time.Time
+	syncEnd   time.Time
+	items     map[string]*calendar.Event
+	// defaultReminders are the calendar's popup reminders for events that use the default
+	defaultReminders []time.Duration
+}
+
+// syncCalendar brings the cached events of a calendar up to date for [start, end].
+// It uses the sync token when the window is already covered and falls back to a
+// full sync when the window moved past the synced range or the token expired (410 Gone).
+func (c *Client) syncCalendar(ctx context.Context, calendarID string, start, end time.Time) (*calendarCache, error) {
+	cache := c.calendars[calendarID]
+	if cache != nil && cache.syncToken != "" && !start.Before(cache.syncStart) && !end.After(cache.syncEnd) {
+		err := c.incrementalSync(ctx, calendarID, cache)
+		if err == nil {
+			return cache, nil
+		}
+		if !isGone(err) {
+			return nil, err
+		}
+	}
+
+	cache = &calendarCache{
+		syncStart: start,
+		syncEnd:   end.Add(googleSyncMargin),
+		location:  time.Local,
+		items:     make(map[string]*calendar.Event),
+	}
+	if err := c.fullSync(ctx, calendarID, cache); err != nil {
+		delete(c.calendars, calendarID)
+		return nil, err
+	}
+	c.calendars[calendarID] = cache
+	return cache, nil
+}
+
+func (c *Client) fullSync(ctx context.Context, calendarID string, cache *calendarCache) error {
+	call := c.service.Events.List(calendarID).
+		TimeMin(cache.syncStart.Format(time.RFC3339)).
+		TimeMax(cache.syncEnd.Format(time.RFC3339)).
+		SingleEvents(true).
+		MaxResults(250).
+		Fields(eventFields)
+	return c.listPages(ctx, call, cache)
+}
+
+func (c *Client) incrementalSync(ctx context.Context, calendarID string, cache *calendarCache) error {
+	// Query parameters must match the full sync, except the time range which is not allowed with a sync token
+	call := c.service.Events.List(calendarID).
+		SyncToken(cache.syncToken).
+		SingleEvents(true).
+		MaxResults(250).
+		Fields(eventFields)
+	return c.listPages(ctx, call, cache)
+}
+
+// listPages follows nextPageToken until the last page, applying each item to the cache.
+// Cancelled items are removed. The sync token is only stored once all pages are applied.
+func (c *Client) listPages(ctx context.Context, call *calendar.EventsListCall, cache *calendarCache) error {
+	pageToken := ""
+	for {
+		events, err := call.PageToken(pageToken).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+
+		cache.name = events.Summary
+		cache.defaultReminders = popupReminders(events.DefaultReminders)
+		if events.TimeZone != "" {
+			if loc, err := time.LoadLocation(events.TimeZone); err == nil {
+				cache.location = loc
+			}
+		}
+		for _, item := range events.Items {
+			if item.Status == "cancelled" {
+				delete(cache.items, item.Id)
+				continue
+			}
+			cache.items[item.Id] = item
+		}
+
+		if events.NextPageToken == "" {
+			cache.syncToken = events.NextSyncToken
+			return nil
+		}
+		pageToken = events.NextPageToken
+	}
+}
+
+func isGone(err error) bool {
+	var gErr *googleapi.Error
+	return errors.As(err, &gErr) && gErr.Code == http.StatusGone
+}
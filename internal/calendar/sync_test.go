@@ -0,0 +1,387 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeCalendarAPI serves Events.List for a single calendar.
+type fakeCalendarAPI struct {
+	handler func(q map[string]string) (int, any)
+	queries []map[string]string
+}
+
+func (f *fakeCalendarAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		q[key] = values[0]
+	}
+	f.queries = append(f.queries, q)
+
+	status, body := f.handler(q)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestClient(t *testing.T, api http.Handler, calendarIDs []string) *Client {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	service, err := calendar.NewService(context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithHTTPClient(server.Client()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return newClient(service, calendarIDs)
+}
+
+func meetItem(id, summary string, start time.Time) map[string]any {
+	return map[string]any{
+		"id":          id,
+		"iCalUID":     id + "@google.com",
+		"status":      "confirmed",
+		"summary":     summary,
+		"start":       map[string]string{"dateTime": start.Format(time.RFC3339)},
+		"end":         map[string]string{"dateTime": start.Add(30 * time.Minute).Format(time.RFC3339)},
+		"hangoutLink": "https://meet.google.com/" + id,
+		"organizer":   map[string]bool{"self": true},
+	}
+}
+
+func titles(events []Event) []string {
+	var result []string
+	for _, e := range events {
+		result = append(result, e.Title)
+	}
+	return result
+}
+
+func TestClientIncrementalSync(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		switch q["syncToken"] {
+		case "":
+			return http.StatusOK, map[string]any{
+				"summary":       "Work",
+				"items":         []any{meetItem("a", "Standup", start), meetItem("b", "Review", start.Add(time.Hour))},
+				"nextSyncToken": "token-1",
+			}
+		case "token-1":
+			return http.StatusOK, map[string]any{
+				"items": []any{
+					map[string]any{"id": "a", "status": "cancelled"},
+					meetItem("c", "Planning", start.Add(2*time.Hour)),
+				},
+				"nextSyncToken": "token-2",
+			}
+		case "token-2":
+			// Sync token expired
+			return http.StatusGone, map[string]any{"error": map[string]any{"code": 410, "message": "Sync token is no longer valid"}}
+		}
+		t.Errorf("unexpected query: %v", q)
+		return http.StatusBadRequest, nil
+	}
+
+	client := newTestClient(t, api, nil)
+	ctx := context.Background()
+
+	got, err := client.GetEventsInRange(ctx, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("full sync failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Standup", "Review"}, titles(got)); diff != "" {
+		t.Errorf("full sync events mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("Work", got[0].CalendarName); diff != "" {
+		t.Errorf("calendar name mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = client.GetEventsInRange(ctx, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("incremental sync failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Review", "Planning"}, titles(got)); diff != "" {
+		t.Errorf("incremental sync events mismatch (-want +got):\n%s", diff)
+	}
+
+	// 410 Gone falls back to a full sync
+	got, err = client.GetEventsInRange(ctx, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("resync failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Standup", "Review"}, titles(got)); diff != "" {
+		t.Errorf("resync events mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(api.queries) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(api.queries))
+	}
+	for i, q := range api.queries {
+		if q["fields"] != eventFields {
+			t.Errorf("request %d: fields = %q, want field mask", i, q["fields"])
+		}
+		hasSyncToken := q["syncToken"] != ""
+		hasTimeRange := q["timeMin"] != "" && q["timeMax"] != ""
+		if hasSyncToken == hasTimeRange {
+			t.Errorf("request %d: sync token and time range must not be combined: %v", i, q)
+		}
+	}
+}
+
+func TestClientSyncWindowMovedTriggersFullSync(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	var fullSyncs int
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		if q["syncToken"] == "" {
+			fullSyncs++
+		}
+		return http.StatusOK, map[string]any{
+			"items":         []any{meetItem("a", "Standup", start)},
+			"nextSyncToken": "token",
+		}
+	}
+
+	client := newTestClient(t, api, nil)
+	ctx := context.Background()
+
+	if _, err := client.GetEventsInRange(ctx, time.Hour, time.Hour); err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+	// A lookahead beyond the synced range requires a new full sync
+	if _, err := client.GetEventsInRange(ctx, time.Hour, 7*24*time.Hour); err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+
+	if diff := cmp.Diff(2, fullSyncs); diff != "" {
+		t.Errorf("full sync count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientFollowsPagination(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		switch q["pageToken"] {
+		case "":
+			return http.StatusOK, map[string]any{
+				"items":         []any{meetItem("a", "First", start)},
+				"nextPageToken": "page-2",
+			}
+		case "page-2":
+			return http.StatusOK, map[string]any{
+				"items":         []any{meetItem("b", "Second", start.Add(time.Hour))},
+				"nextPageToken": "page-3",
+			}
+		case "page-3":
+			return http.StatusOK, map[string]any{
+				"items":         []any{meetItem("c", "Tomorrow", start.Add(20*time.Hour))},
+				"nextSyncToken": "token",
+			}
+		}
+		t.Errorf("unexpected query: %v", q)
+		return http.StatusBadRequest, nil
+	}
+
+	client := newTestClient(t, api, nil)
+	got, err := client.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"First", "Second", "Tomorrow"}, titles(got)); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("token", client.calendars["primary"].syncToken); diff != "" {
+		t.Errorf("sync token mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientAccountJoinsAsAccount(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		if q["fields"] == "id" {
+			// Calendars.Get("primary")
+			return http.StatusOK, map[string]any{"id": "me@work.example.com"}
+		}
+		return http.StatusOK, map[string]any{
+			"items":         []any{meetItem("a", "Standup", start)},
+			"nextSyncToken": "token",
+		}
+	}
+
+	client := newTestClient(t, api, nil)
+	client.account = "work"
+
+	got, err := client.GetEventsInRange(context.Background(), time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(got))
+	}
+
+	if diff := cmp.Diff("work", got[0].Account); diff != "" {
+		t.Errorf("account mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("https://meet.google.com/a?authuser=me%40work.example.com", got[0].JoinURL); diff != "" {
+		t.Errorf("join URL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientReminders(t *testing.T) {
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	overridden := meetItem("b", "Review", start)
+	overridden["reminders"] = map[string]any{
+		"useDefault": false,
+		"overrides": []map[string]any{
+			{"method": "email", "minutes": 60},
+			{"method": "popup", "minutes": 5},
+		},
+	}
+	silenced := meetItem("c", "Lunch", start.Add(time.Minute))
+	silenced["reminders"] = map[string]any{"useDefault": false}
+	defaulted := meetItem("a", "Standup", start.Add(-time.Minute))
+	defaulted["reminders"] = map[string]any{"useDefault": true}
+
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		return http.StatusOK, map[string]any{
+			"items":            []any{defaulted, overridden, silenced},
+			"defaultReminders": []map[string]any{{"method": "popup", "minutes": 10}},
+			"nextSyncToken":    "token",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		reminders bool
+		want      [][]time.Duration
+	}{
+		{
+			name: "ignored by default",
+			want: [][]time.Duration{nil, nil, nil},
+		},
+		{
+			name:      "popup overrides, then calendar defaults",
+			reminders: true,
+			want:      [][]time.Duration{{10 * time.Minute}, {5 * time.Minute}, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, api, nil)
+			client.reminders = tt.reminders
+
+			events, err := client.GetEventsInRange(context.Background(), time.Hour, 2*time.Hour)
+			if err != nil {
+				t.Fatalf("GetEventsInRange failed: %v", err)
+			}
+
+			var got [][]time.Duration
+			for _, event := range events {
+				got = append(got, event.Reminders)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("reminders mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientOutOfOffice(t *testing.T) {
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	ooo := func(id string, self bool) map[string]any {
+		return map[string]any{
+			"id":        id,
+			"status":    "confirmed",
+			"summary":   "Vacation " + id,
+			"eventType": "outOfOffice",
+			"start":     map[string]string{"dateTime": start.Format(time.RFC3339)},
+			"end":       map[string]string{"dateTime": start.Add(48 * time.Hour).Format(time.RFC3339)},
+			"organizer": map[string]any{"self": self},
+		}
+	}
+
+	api := &fakeCalendarAPI{}
+	api.handler = func(q map[string]string) (int, any) {
+		return http.StatusOK, map[string]any{
+			// A colleague's out-of-office on a shared calendar does not count
+			"items":         []any{ooo("mine", true), ooo("colleague", false), meetItem("a", "Standup", start)},
+			"nextSyncToken": "token",
+		}
+	}
+
+	client := newTestClient(t, api, nil)
+	got, err := client.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetEventsInRange failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Standup"}, titles(got)); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []Period{{Title: "Vacation mine", Start: start, End: start.Add(48 * time.Hour)}}
+	periods := client.OutOfOffice(time.Now(), time.Now().Add(24*time.Hour))
+	if diff := cmp.Diff(want, periods, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("out of office mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientKeepsWorkingCalendars(t *testing.T) {
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// A shared calendar that is no longer shared
+		if strings.Contains(r.URL.Path, "unshared") {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 404, "message": "Not Found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"summary":       "Work",
+			"items":         []any{meetItem("a", "Standup", start)},
+			"nextSyncToken": "token-1",
+		})
+	})
+
+	client := newTestClient(t, api, []string{"primary", "unshared@group.calendar.google.com"})
+	events, err := client.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour)
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialError, got %v", err)
+	}
+	if diff := cmp.Diff([]string{"Standup"}, titles(events)); diff != "" {
+		t.Errorf("titles mismatch (-want +got):\n%s", diff)
+	}
+
+	// The fetch only fails when every calendar fails
+	client = newTestClient(t, api, []string{"unshared@group.calendar.google.com"})
+	if _, err := client.GetEventsInRange(context.Background(), time.Hour, 24*time.Hour); err == nil || errors.As(err, &partial) {
+		t.Errorf("expected a full failure, got %v", err)
+	}
+}
@@ -1,30 +1,83 @@
 package calendar
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"golang.org/x/oauth2"
 )
 
-func TokenPath() (string, error) {
-	configDir, err := ConfigDir()
-	if err != nil {
-		return "", err
+// GraphTokenKey identifies the Microsoft Graph token in a TokenStore.
+const GraphTokenKey = "graph-token"
+
+// ErrTokenNotFound is returned by TokenStore.Load when no token is stored under the key.
+var ErrTokenNotFound = errors.New("token not found")
+
+// ErrLoggedOut is returned by a persistent token source whose token was removed
+// from the store, e.g. by ooi logout, while it was in use.
+var ErrLoggedOut = errors.New("logged out, token was removed")
+
+// TokenStore persists OAuth tokens under a key such as "token" or "token-work".
+type TokenStore interface {
+	Load(key string) (*oauth2.Token, error)
+	Save(key string, token *oauth2.Token) error
+	Delete(key string) error
+}
+
+// TokenKey returns the key of the Google token for the account. Empty means the default account.
+func TokenKey(account string) string {
+	if account == "" {
+		return "token"
 	}
-	return filepath.Join(configDir, "token.json"), nil
+	return "token-" + account
+}
+
+// FileTokenStore stores tokens as plaintext <key>.json files in a directory.
+type FileTokenStore struct {
+	Dir string
 }
 
-func LoadToken() (*oauth2.Token, error) {
-	path, err := TokenPath()
+// NewFileTokenStore returns a store in the config directory, where tokens were always kept.
+func NewFileTokenStore() (*FileTokenStore, error) {
+	configDir, err := ConfigDir()
 	if err != nil {
 		return nil, err
 	}
+	return &FileTokenStore{Dir: configDir}, nil
+}
+
+func (s *FileTokenStore) path(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
+
+func (s *FileTokenStore) Load(key string) (*oauth2.Token, error) {
+	return loadTokenFile(s.path(key))
+}
+
+func (s *FileTokenStore) Save(key string, token *oauth2.Token) error {
+	return saveTokenFile(s.path(key), token)
+}
+
+func (s *FileTokenStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete token file: %w", err)
+	}
+	return nil
+}
 
+func loadTokenFile(path string) (*oauth2.Token, error) {
 	f, err := os.Open(path)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrTokenNotFound, filepath.Base(path))
+		}
 		return nil, fmt.Errorf("failed to open token file: %w", err)
 	}
 	defer f.Close()
@@ -37,30 +90,178 @@ func LoadToken() (*oauth2.Token, error) {
 	return &token, nil
 }
 
-func SaveToken(token *oauth2.Token) error {
-	path, err := TokenPath()
+// saveTokenFile writes the token atomically, so a concurrent reader never sees a partial file.
+func saveTokenFile(path string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to encode token: %w", err)
 	}
+	return writeFileAtomic(path, data)
+}
 
-	configDir, err := ConfigDir()
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create token file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace token file: %w", err)
+	}
+
+	return nil
+}
+
+// migratingStore moves tokens from legacy plaintext files into store the first time they are loaded.
+type migratingStore struct {
+	TokenStore
+	legacy *FileTokenStore
+}
+
+// WithMigration returns a store that falls back to the plaintext token files in the
+// config directory and moves any token found there into store.
+func WithMigration(store TokenStore) (TokenStore, error) {
+	if _, ok := store.(*FileTokenStore); ok {
+		return store, nil
+	}
+	legacy, err := NewFileTokenStore()
+	if err != nil {
+		return nil, err
+	}
+	return &migratingStore{TokenStore: store, legacy: legacy}, nil
+}
+
+func (s *migratingStore) Load(key string) (*oauth2.Token, error) {
+	token, err := s.TokenStore.Load(key)
+	if !errors.Is(err, ErrTokenNotFound) {
+		return token, err
+	}
+	migrated, err := MigrateToken(s.legacy, s.TokenStore, key)
 	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		log.Printf("Moved %s.json into the token store", key)
+	}
+	return s.TokenStore.Load(key)
+}
+
+func (s *migratingStore) Delete(key string) error {
+	if err := s.TokenStore.Delete(key); err != nil {
 		return err
 	}
+	return s.legacy.Delete(key)
+}
 
-	if err := os.MkdirAll(configDir, 0o700); err != nil {
-		return fmt.Errorf("failed to create config dir: %w", err)
+// MigrateToken moves the token stored under key from one store to another.
+// It reports false if from has no such token.
+func MigrateToken(from, to TokenStore, key string) (bool, error) {
+	token, err := from.Load(key)
+	if errors.Is(err, ErrTokenNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := to.Save(key, token); err != nil {
+		return false, fmt.Errorf("failed to migrate %s: %w", key, err)
+	}
+	// Make sure the token can be read back before removing the only other copy
+	if _, err := to.Load(key); err != nil {
+		return false, fmt.Errorf("failed to migrate %s: %w", key, err)
+	}
+	if err := from.Delete(key); err != nil {
+		return false, fmt.Errorf("failed to remove migrated %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// PersistentClient returns an HTTP client authorized with token whose refreshed
+// tokens are saved to store under key. The store is shared with other ooi
+// processes: a token refreshed by one process is picked up by the others
+// instead of refreshing again.
+func PersistentClient(ctx context.Context, config *oauth2.Config, store TokenStore, key string, token *oauth2.Token) *http.Client {
+	return oauth2.NewClient(ctx, PersistentTokenSource(ctx, config, store, key, token))
+}
+
+// PersistentTokenSource is the token source used by PersistentClient.
+func PersistentTokenSource(ctx context.Context, config *oauth2.Config, store TokenStore, key string, token *oauth2.Token) oauth2.TokenSource {
+	return &storeTokenSource{
+		ctx:    ctx,
+		config: config,
+		store:  store,
+		key:    key,
+		token:  token,
 	}
+}
+
+type storeTokenSource struct {
+	ctx    context.Context
+	config *oauth2.Config
+	store  TokenStore
+	key    string
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (s *storeTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	// Hold the lock while refreshing so that two processes never redeem the
+	// same refresh token, which fails when the provider rotates refresh tokens.
+	configDir, err := ConfigDir()
 	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
+		return nil, err
 	}
-	defer f.Close()
+	if err := os.MkdirAll(configDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create config dir: %w", err)
+	}
+	unlock, err := lockFile(filepath.Join(configDir, s.key+".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock token: %w", err)
+	}
+	defer unlock()
 
-	if err := json.NewEncoder(f).Encode(token); err != nil {
-		return fmt.Errorf("failed to encode token: %w", err)
+	// Another process may have refreshed the token already, or removed it
+	stored, err := s.store.Load(s.key)
+	switch {
+	case err == nil:
+		s.token = stored
+		if stored.Valid() {
+			return stored, nil
+		}
+	case errors.Is(err, ErrTokenNotFound):
+		// Refreshing would save the token again and undo the logout
+		return nil, fmt.Errorf("%w: %s", ErrLoggedOut, s.key)
 	}
 
-	return nil
+	token, err := s.config.TokenSource(s.ctx, s.token).Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Save(s.key, token); err != nil {
+		log.Printf("Failed to save refreshed token: %v", err)
+	}
+	s.token = token
+	return token, nil
 }
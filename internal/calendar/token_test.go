@@ -0,0 +1,230 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+func TestPersistentClientSavesRefreshedToken(t *testing.T) {
+	var refreshes int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		// The provider rotates the refresh token
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","token_type":"Bearer","expires_in":3600}`, refreshes, refreshes)
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	store := &FileTokenStore{Dir: t.TempDir()}
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Hour)}
+	if err := store.Save("token", expired); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// The daemon refreshes the token
+	daemonClient := PersistentClient(ctx, config, store, "token", expired)
+	if _, err := daemonClient.Get(api.URL); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	saved, err := store.Load("token")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff("access-1", saved.AccessToken); diff != "" {
+		t.Errorf("saved access token mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("refresh-1", saved.RefreshToken); diff != "" {
+		t.Errorf("saved refresh token mismatch (-want +got):\n%s", diff)
+	}
+
+	// A CLI command started with the stale token reuses the daemon's refreshed one
+	cliClient := PersistentClient(ctx, config, store, "token", expired)
+	if _, err := cliClient.Get(api.URL); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if diff := cmp.Diff(1, refreshes); diff != "" {
+		t.Errorf("refresh count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Bearer access-1", "Bearer access-1"}, authHeaders); diff != "" {
+		t.Errorf("authorization headers mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPersistentClientAfterLogout(t *testing.T) {
+	var refreshes int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	store := &FileTokenStore{Dir: t.TempDir()}
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+	token := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Hour)}
+	if err := store.Save("token", token); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	source := PersistentTokenSource(context.Background(), config, store, "token", token)
+
+	// ooi logout removes the token while the daemon still holds an expired one
+	if err := store.Delete("token"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	_, err := source.Token()
+	if !errors.Is(err, ErrLoggedOut) {
+		t.Errorf("expected ErrLoggedOut, got %v", err)
+	}
+	if diff := cmp.Diff(KindTokenExpired, Classify(err)); diff != "" {
+		t.Errorf("kind mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := store.Load("token"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected the store to stay empty, got %v", err)
+	}
+	if diff := cmp.Diff(0, refreshes); diff != "" {
+		t.Errorf("refresh count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewEncryptedFileStore(dir, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileStore failed: %v", err)
+	}
+	store.iterations = 1000
+
+	want := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}
+	if err := store.Save("token", want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "token.json.enc"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(raw), "refresh") {
+		t.Errorf("token file contains the plaintext refresh token: %s", raw)
+	}
+
+	got, err := store.Load("token")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(want.RefreshToken, got.RefreshToken); diff != "" {
+		t.Errorf("refresh token mismatch (-want +got):\n%s", diff)
+	}
+
+	wrong, _ := NewEncryptedFileStore(dir, []byte("wrong"))
+	if _, err := wrong.Load("token"); err == nil {
+		t.Error("expected an error with the wrong passphrase")
+	}
+
+	if _, err := store.Load("token-work"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+}
+
+func TestMigrateToken(t *testing.T) {
+	legacy := &FileTokenStore{Dir: t.TempDir()}
+	store, _ := NewEncryptedFileStore(t.TempDir(), []byte("secret"))
+	store.iterations = 1000
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+	if err := legacy.Save("token", token); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	migrating := &migratingStore{TokenStore: store, legacy: legacy}
+	got, err := migrating.Load("token")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff("refresh", got.RefreshToken); diff != "" {
+		t.Errorf("refresh token mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := legacy.Load("token"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected the plaintext token to be removed, got %v", err)
+	}
+	if _, err := store.Load("token"); err != nil {
+		t.Errorf("expected the token in the store, got %v", err)
+	}
+
+	if _, err := migrating.Load("graph-token"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+}
+
+// fakeKeyring emulates secret-tool with an in-memory map.
+type fakeKeyring struct {
+	secrets map[string]string
+}
+
+func (k *fakeKeyring) run(stdin string, name string, args ...string) ([]byte, error) {
+	key := args[len(args)-1]
+	switch args[0] {
+	case "store":
+		k.secrets[key] = stdin
+	case "lookup":
+		secret, ok := k.secrets[key]
+		if !ok {
+			return nil, &commandError{name: name, err: errors.New("exit status 1"), code: 1}
+		}
+		return []byte(secret), nil
+	case "clear":
+		delete(k.secrets, key)
+	}
+	return nil, nil
+}
+
+func TestSecretServiceStore(t *testing.T) {
+	keyring := &fakeKeyring{secrets: make(map[string]string)}
+	store := &SecretServiceStore{run: keyring.run}
+
+	if _, err := store.Load("token"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+	if err := store.Save("token", token); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := store.Load("token")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff("refresh", got.RefreshToken); diff != "" {
+		t.Errorf("refresh token mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := store.Delete("token"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load("token"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound after Delete, got %v", err)
+	}
+}
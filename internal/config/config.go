@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/knwoop/ooi/internal/calendar"
+)
+
+// Config is the user configuration stored in config.toml
+type Config struct {
+	// Calendars lists the Google Calendar IDs to watch with the default account
+	Calendars []string `toml:"calendars"`
+
+	// Accounts lists additional Google accounts authenticated with 'ooi auth --account'
+	Accounts []AccountConfig `toml:"accounts"`
+
+	// Lookahead is how far ahead events are fetched. It is a rolling horizon, so
+	// tomorrow morning's meetings are known before midnight.
+	Lookahead Duration `toml:"lookahead"`
+
+	// FetchInterval is how often the daemon fetches events
+	FetchInterval Duration `toml:"fetch_interval"`
+
+	// MissedLookback is how long after the start a meeting that was not alerted,
+	// e.g. while the Mac was asleep, is still announced
+	MissedLookback Duration `toml:"missed_lookback"`
+
+	// StatusLookback and StatusLookahead bound the meetings shown by 'ooi status'
+	StatusLookback  Duration `toml:"status_lookback"`
+	StatusLookahead Duration `toml:"status_lookahead"`
+
+	// MenubarTitleLength is how many characters of the meeting title the menu bar shows
+	MenubarTitleLength int `toml:"menubar_title_length"`
+
+	// Reminders is when and how meetings are announced. Defaults to a join dialog one minute before.
+	Reminders []Reminder `toml:"reminder"`
+
+	// GoogleReminders times the join dialog from the popup reminders set in Google
+	// Calendar, falling back to the calendar's default reminders and then to Reminders
+	GoogleReminders bool `toml:"google_reminders"`
+
+	// AlertAllDay enables alerts for all-day events, which are ignored by default
+	AlertAllDay bool `toml:"alert_all_day"`
+
+	// DisplayTimeZone is the IANA time zone used by 'ooi status' and the menu bar. Empty means local time.
+	DisplayTimeZone string `toml:"display_time_zone"`
+
+	// QuietHours downgrades or suppresses alerts on weekends and outside working hours
+	QuietHours QuietHours `toml:"quiet_hours"`
+
+	// OutOfOffice controls alerts while an out-of-office event is on your calendar
+	OutOfOffice OutOfOffice `toml:"out_of_office"`
+
+	// RSVP enables responding to invitations from ooi. It needs write access
+	// to events, granted by running 'ooi auth' again after enabling it.
+	RSVP bool `toml:"rsvp"`
+
+	// AutoJoin times joining the meetings selected by the [auto_join] rules in rules.toml
+	AutoJoin AutoJoin `toml:"auto_join"`
+
+	// NativeApps lists conference providers (zoom, teams) whose links open in the desktop app
+	NativeApps []string `toml:"native_apps"`
+
+	// ICS lists .ics files and ICS subscription URLs to watch in addition to Google Calendar
+	ICS []ICSConfig `toml:"ics"`
+
+	// CalDAV lists CalDAV calendar collections to watch
+	CalDAV []CalDAVConfig `toml:"caldav"`
+
+	// Microsoft enables the Microsoft 365 / Outlook calendar via Microsoft Graph
+	Microsoft MicrosoftConfig `toml:"microsoft"`
+
+	// TokenStore selects where OAuth tokens are stored
+	TokenStore TokenStoreConfig `toml:"token_store"`
+}
+
+// DisplayLocation returns the time zone to display times in.
+func (c *Config) DisplayLocation() *time.Location {
+	if c.DisplayTimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.DisplayTimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+type AccountConfig struct {
+	// Name identifies the token stored by 'ooi auth --account' and labels its events
+	Name      string   `toml:"name"`
+	Calendars []string `toml:"calendars"`
+	// Email is used to open Meet links as this account. Looked up automatically if empty.
+	Email string `toml:"email"`
+}
+
+var accountNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidAccountName reports whether name can be used as an account name.
+// Names become part of file names, so only letters, digits, '-' and '_' are allowed.
+func ValidAccountName(name string) bool {
+	return accountNamePattern.MatchString(name)
+}
+
+type AutoJoin struct {
+	// Lead is how long before the start meetings are joined; zero joins at the start time
+	Lead Duration `toml:"lead"`
+	// CancelWindow is how long the notice is shown before joining, during which it can be cancelled
+	CancelWindow Duration `toml:"cancel_window"`
+}
+
+type ICSConfig struct {
+	Name  string `toml:"name"`
+	Path  string `toml:"path"`
+	URL   string `toml:"url"`
+	Email string `toml:"email"`
+}
+
+type CalDAVConfig struct {
+	// Name identifies the app password stored by 'ooi auth caldav'
+	Name  string `toml:"name"`
+	URL   string `toml:"url"`
+	Email string `toml:"email"`
+}
+
+type MicrosoftConfig struct {
+	// ClientID of a public client app registered in Microsoft Entra ID. Empty disables the source.
+	ClientID string `toml:"client_id"`
+	// Tenant is "common", "organizations", "consumers" or a tenant ID
+	Tenant  string `toml:"tenant"`
+	BaseURL string `toml:"base_url"`
+	Name    string `toml:"name"`
+}
+
+func (c MicrosoftConfig) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// Location returns the file path or URL of the calendar.
+func (c ICSConfig) Location() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return c.Path
+}
+
+func Default() *Config {
+	return &Config{
+		Calendars:          []string{"primary"},
+		Lookahead:          Duration{24 * time.Hour},
+		FetchInterval:      Duration{3 * time.Minute},
+		MissedLookback:     Duration{time.Hour},
+		StatusLookback:     Duration{2 * time.Hour},
+		StatusLookahead:    Duration{3 * time.Hour},
+		MenubarTitleLength: 20,
+		Reminders:          DefaultReminders(),
+		QuietHours: QuietHours{
+			Action: ActionNotify,
+		},
+		OutOfOffice: OutOfOffice{
+			Action: ActionSuppress,
+		},
+		AutoJoin: AutoJoin{
+			CancelWindow: Duration{10 * time.Second},
+		},
+		TokenStore: TokenStoreConfig{
+			Backend: BackendAuto,
+		},
+	}
+}
+
+func Path() (string, error) {
+	configDir, err := calendar.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.toml"), nil
+}
+
+// Load reads config.toml, falling back to defaults when the file does not exist,
+// applies OOI_* environment variable overrides and validates the result.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.fillDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile decodes the config file at path over the defaults, without
+// environment overrides or validation. Unknown keys are an error.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %q in %s", undecoded[0].String(), path)
+	}
+	return cfg, nil
+}
+
+// fillDefaults restores defaults for settings that were set to empty.
+func (c *Config) fillDefaults() {
+	if len(c.Calendars) == 0 {
+		c.Calendars = Default().Calendars
+	}
+	if len(c.Reminders) == 0 {
+		c.Reminders = DefaultReminders()
+	}
+}
+
+// Validate checks the settings.
+func (c *Config) Validate() error {
+	if c.Lookahead.Duration < time.Minute {
+		return fmt.Errorf("lookahead must be at least 1m, got %s", c.Lookahead.Duration)
+	}
+	if c.FetchInterval.Duration < 30*time.Second {
+		return fmt.Errorf("fetch_interval must be at least 30s, got %s", c.FetchInterval.Duration)
+	}
+	if c.MissedLookback.Duration < 0 {
+		return fmt.Errorf("missed_lookback must not be negative, got %s", c.MissedLookback.Duration)
+	}
+	if c.StatusLookback.Duration < 0 || c.StatusLookahead.Duration < 0 {
+		return fmt.Errorf("status_lookback and status_lookahead must not be negative")
+	}
+	if c.MenubarTitleLength < 1 {
+		return fmt.Errorf("menubar_title_length must be at least 1, got %d", c.MenubarTitleLength)
+	}
+
+	if c.DisplayTimeZone != "" {
+		if _, err := time.LoadLocation(c.DisplayTimeZone); err != nil {
+			return fmt.Errorf("invalid display_time_zone %q: %w", c.DisplayTimeZone, err)
+		}
+	}
+
+	if q := c.QuietHours; q.Start.set != q.End.set {
+		return fmt.Errorf("quiet_hours: both start and end must be set")
+	}
+	if !c.QuietHours.Action.valid() {
+		return fmt.Errorf("quiet_hours: invalid action %q", c.QuietHours.Action)
+	}
+	if !c.OutOfOffice.Action.valid() {
+		return fmt.Errorf("out_of_office: invalid action %q", c.OutOfOffice.Action)
+	}
+
+	if err := ValidateReminders(c.Reminders); err != nil {
+		return err
+	}
+
+	if c.AutoJoin.Lead.Duration < 0 || c.AutoJoin.CancelWindow.Duration < 0 {
+		return fmt.Errorf("auto_join: lead and cancel_window must not be negative")
+	}
+
+	if !c.TokenStore.Backend.valid() {
+		return fmt.Errorf("token_store: invalid backend %q", c.TokenStore.Backend)
+	}
+
+	seen := make(map[string]bool)
+	for i, account := range c.Accounts {
+		if !ValidAccountName(account.Name) {
+			return fmt.Errorf("accounts[%d]: invalid name %q", i, account.Name)
+		}
+		if seen[account.Name] {
+			return fmt.Errorf("accounts[%d]: duplicate name %q", i, account.Name)
+		}
+		seen[account.Name] = true
+	}
+
+	for i, ics := range c.ICS {
+		if (ics.Path == "") == (ics.URL == "") {
+			return fmt.Errorf("ics[%d]: exactly one of path or url must be set", i)
+		}
+	}
+
+	for i, caldav := range c.CalDAV {
+		if caldav.Name == "" || caldav.URL == "" {
+			return fmt.Errorf("caldav[%d]: name and url must be set", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "ooi", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	writeConfig(t, `
+fetch_interval = "5m"
+calendars = []
+
+[quiet_hours]
+start = "19:00"
+end = "09:00"
+`)
+	t.Setenv("OOI_MISSED_LOOKBACK", "30m")
+	t.Setenv("OOI_QUIET_HOURS_ACTION", "suppress")
+	t.Setenv("OOI_NATIVE_APPS", "zoom, teams")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if diff := cmp.Diff(5*time.Minute, cfg.FetchInterval.Duration); diff != "" {
+		t.Errorf("fetch_interval mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(30*time.Minute, cfg.MissedLookback.Duration); diff != "" {
+		t.Errorf("missed_lookback mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ActionSuppress, cfg.QuietHours.Action); diff != "" {
+		t.Errorf("quiet_hours.action mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"zoom", "teams"}, cfg.NativeApps); diff != "" {
+		t.Errorf("native_apps mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"primary"}, cfg.Calendars); diff != "" {
+		t.Errorf("calendars mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(20, cfg.MenubarTitleLength); diff != "" {
+		t.Errorf("menubar_title_length mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		env  map[string]string
+	}{
+		{name: "unknown key", data: "fetch_intervall = \"5m\"\n"},
+		{name: "unknown nested key", data: "[quiet_hours]\nweekend = true\n"},
+		{name: "fetch interval too short", data: "fetch_interval = \"1s\"\n"},
+		{name: "title length", data: "menubar_title_length = 0\n"},
+		{name: "invalid env value", env: map[string]string{"OOI_RSVP": "maybe"}},
+		{name: "invalid env override", env: map[string]string{"OOI_QUIET_HOURS_ACTION": "mute"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.data)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := Load(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	cfg := Default()
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{key: "lookahead", value: "48h0m0s"},
+		{key: "alert_all_day", value: "true"},
+		{key: "menubar_title_length", value: "30"},
+		{key: "calendars", value: "primary,team@example.com"},
+		{key: "quiet_hours.start", value: "19:00"},
+		{key: "token_store.backend", value: "file"},
+	}
+
+	for _, tt := range tests {
+		if err := cfg.Set(tt.key, tt.value); err != nil {
+			t.Fatalf("Set(%q) failed: %v", tt.key, err)
+		}
+		got, err := cfg.Get(tt.key)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", tt.key, err)
+		}
+		if diff := cmp.Diff(tt.value, got); diff != "" {
+			t.Errorf("Get(%q) mismatch (-want +got):\n%s", tt.key, diff)
+		}
+	}
+
+	for _, key := range []string{"nope", "quiet_hours", "accounts", "lookahead.x"} {
+		if _, err := cfg.Get(key); err == nil {
+			t.Errorf("Get(%q): expected an error", key)
+		}
+	}
+	if err := cfg.Set("menubar_title_length", "long"); err == nil {
+		t.Error("expected an error for an invalid number")
+	}
+}
+
+func TestSetInFile(t *testing.T) {
+	path := writeConfig(t, `
+calendars = ["primary"]
+
+[[accounts]]
+name = "work"
+`)
+
+	err := SetInFile(path, map[string]string{
+		"quiet_hours.start": "19:00",
+		"quiet_hours.end":   "09:00",
+		"fetch_interval":    "5m",
+	})
+	if err != nil {
+		t.Fatalf("SetInFile failed: %v", err)
+	}
+	if err := SetInFile(path, map[string]string{"fetch_interval": ""}); err != nil {
+		t.Fatalf("SetInFile failed: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if diff := cmp.Diff("19:00", cfg.QuietHours.Start.String()); diff != "" {
+		t.Errorf("quiet_hours.start mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(3*time.Minute, cfg.FetchInterval.Duration); diff != "" {
+		t.Errorf("fetch_interval mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]AccountConfig{{Name: "work"}}, cfg.Accounts); diff != "" {
+		t.Errorf("accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if diff := cmp.Diff(os.FileMode(0o600), info.Mode().Perm()); diff != "" {
+		t.Errorf("config.toml mode mismatch (-want +got):\n%s", diff)
+	}
+
+	// Invalid results are not written
+	if err := SetInFile(path, map[string]string{"quiet_hours.end": ""}); err == nil {
+		t.Error("expected an error for quiet hours without an end")
+	}
+	if err := SetInFile(path, map[string]string{"fetch_interval": "1s"}); err == nil {
+		t.Error("expected an error for a short fetch interval")
+	}
+}
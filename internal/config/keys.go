@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPrefix starts the environment variables that override config.toml, e.g.
+// OOI_FETCH_INTERVAL for fetch_interval and OOI_QUIET_HOURS_ACTION for quiet_hours.action.
+const EnvPrefix = "OOI_"
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+var textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// Keys returns the settings that can be read and written with Get and Set, as
+// dotted TOML keys like "quiet_hours.start". Lists of tables such as
+// [[accounts]] and [[reminder]] are only set in config.toml.
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name := field.Tag.Get("toml")
+			if name == "" {
+				continue
+			}
+			key := prefix + name
+			switch {
+			case scalar(field.Type):
+				keys = append(keys, key)
+			case field.Type.Kind() == reflect.Struct:
+				walk(field.Type, key+".")
+			}
+		}
+	}
+	walk(reflect.TypeFor[Config](), "")
+	return keys
+}
+
+// scalar reports whether values of t are written as a single TOML value.
+func scalar(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// field returns the setting named by the dotted key.
+func (c *Config) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		found := false
+		for j := range v.NumField() {
+			if v.Type().Field(j).Tag.Get("toml") == part {
+				v = v.Field(j)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown key %q", key)
+		}
+		if scalar(v.Type()) {
+			if i != len(parts)-1 {
+				return reflect.Value{}, fmt.Errorf("unknown key %q", key)
+			}
+			return v, nil
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%q is a list of tables, edit config.toml instead", key)
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%q is a table, use one of its keys", key)
+}
+
+// Get returns the value of a setting as it is written on the command line.
+// Lists are comma separated.
+func (c *Config) Get(key string) (string, error) {
+	v, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	if clock, ok := v.Interface().(ClockTime); ok && !clock.set {
+		return "", nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ","), nil
+	default:
+		return v.String(), nil
+	}
+}
+
+// Set parses value into a setting. Lists are comma separated. The result is not
+// validated; call Validate afterwards.
+func (c *Config) Set(key, value string) error {
+	v, err := c.field(key)
+	if err != nil {
+		return err
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if value == "" {
+			v.SetZero()
+			return nil
+		}
+		if err := u.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", key, value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", key, value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		v.SetString(value)
+	}
+	return nil
+}
+
+// tomlValue returns a setting as it is written in config.toml.
+func (c *Config) tomlValue(key string) (any, error) {
+	v, err := c.field(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return c.Get(key)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int:
+		return v.Int(), nil
+	case reflect.Slice:
+		return v.Interface(), nil
+	default:
+		return v.String(), nil
+	}
+}
+
+// applyEnv overrides settings with the OOI_* environment variables that are set.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		value, ok := lookup(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", EnvName(key), err)
+		}
+	}
+	return nil
+}
+
+// SetInFile writes settings, a map of key to value, to the config file at path
+// after validating the result. Settings that must change together, like
+// quiet_hours.start and quiet_hours.end, are set in one call. An empty value
+// removes the key so that the default applies. The file is rewritten, so
+// comments in it are not kept.
+func SetInFile(path string, settings map[string]string) error {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	for key, value := range settings {
+		if value == "" {
+			if value, err = Default().Get(key); err != nil {
+				return err
+			}
+		}
+		if err := cfg.Set(key, value); err != nil {
+			return err
+		}
+	}
+	cfg.fillDefaults()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	table := make(map[string]any)
+	if _, err := toml.DecodeFile(path, &table); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	for key, value := range settings {
+		written, err := cfg.tomlValue(key)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(key, ".")
+		parent := table
+		for _, part := range parts[:len(parts)-1] {
+			sub, ok := parent[part].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				parent[part] = sub
+			}
+			parent = sub
+		}
+		if value == "" {
+			delete(parent, parts[len(parts)-1])
+		} else {
+			parent[parts[len(parts)-1]] = written
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(table); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return writeConfigFile(path, buf.Bytes())
+}
+
+// writeConfigFile replaces the config file atomically, so a crash never leaves a
+// truncated file behind. Like the other files in the config directory it is
+// only readable by the user.
+func writeConfigFile(path string, data []byte) error {
+	// Replace the target of a symlinked config, e.g. one kept with dotfiles
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.toml")
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace config: %w", err)
+	}
+	return nil
+}
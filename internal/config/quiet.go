@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertAction is what happens when a meeting is due.
+type AlertAction string
+
+const (
+	// ActionAlert shows the modal join dialog
+	ActionAlert AlertAction = "alert"
+	// ActionNotify shows a notification banner instead of the dialog
+	ActionNotify AlertAction = "notify"
+	// ActionSuppress does not alert at all
+	ActionSuppress AlertAction = "suppress"
+)
+
+func (a AlertAction) valid() bool {
+	return a == ActionAlert || a == ActionNotify || a == ActionSuppress
+}
+
+// ClockTime is a time of day written as "HH:MM" in config.toml.
+type ClockTime struct {
+	Hour, Minute int
+	set          bool
+}
+
+func (c *ClockTime) UnmarshalText(text []byte) error {
+	t, err := time.Parse("15:04", string(text))
+	if err != nil {
+		return fmt.Errorf("invalid time of day %q, expected HH:MM", text)
+	}
+	*c = ClockTime{Hour: t.Hour(), Minute: t.Minute(), set: true}
+	return nil
+}
+
+func (c ClockTime) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+func (c ClockTime) minutes() int {
+	return c.Hour*60 + c.Minute
+}
+
+// QuietHours is a local schedule during which alerts are downgraded or suppressed.
+type QuietHours struct {
+	// Weekends makes all of Saturday and Sunday quiet
+	Weekends bool `toml:"weekends"`
+	// Start and End bound the quiet time on every day, e.g. 19:00 to 09:00
+	Start ClockTime `toml:"start"`
+	End   ClockTime `toml:"end"`
+	// Action applies to meetings during quiet hours. Defaults to notify.
+	Action AlertAction `toml:"action"`
+}
+
+// Contains reports whether t, in its own location, is within quiet hours, and why.
+func (q QuietHours) Contains(t time.Time) (string, bool) {
+	if q.Weekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return "weekend", true
+	}
+	if !q.Start.set || !q.End.set {
+		return "", false
+	}
+
+	m := t.Hour()*60 + t.Minute()
+	start, end := q.Start.minutes(), q.End.minutes()
+	var quiet bool
+	if start <= end {
+		quiet = m >= start && m < end
+	} else {
+		// The window wraps past midnight
+		quiet = m >= start || m < end
+	}
+	if !quiet {
+		return "", false
+	}
+	return fmt.Sprintf("%s–%s", q.Start, q.End), true
+}
+
+// OutOfOffice controls alerts during out-of-office events on your calendar.
+type OutOfOffice struct {
+	// Action applies to meetings during out-of-office time. Defaults to suppress.
+	Action AlertAction `toml:"action"`
+}
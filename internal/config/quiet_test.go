@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	var q QuietHours
+	if err := q.Start.UnmarshalText([]byte("19:00")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+	if err := q.End.UnmarshalText([]byte("09:00")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+	q.Weekends = true
+
+	tests := []struct {
+		name       string
+		t          time.Time
+		wantReason string
+		wantQuiet  bool
+	}{
+		{
+			name: "working hours",
+			t:    time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC), // Wednesday
+		},
+		{
+			name:       "evening",
+			t:          time.Date(2026, 10, 14, 19, 0, 0, 0, time.UTC),
+			wantReason: "19:00–09:00",
+			wantQuiet:  true,
+		},
+		{
+			name:       "early morning wraps past midnight",
+			t:          time.Date(2026, 10, 15, 8, 59, 0, 0, time.UTC),
+			wantReason: "19:00–09:00",
+			wantQuiet:  true,
+		},
+		{
+			name: "end is exclusive",
+			t:    time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "weekend",
+			t:          time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), // Saturday
+			wantReason: "weekend",
+			wantQuiet:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, quiet := q.Contains(tt.t)
+			if diff := cmp.Diff(tt.wantQuiet, quiet); diff != "" {
+				t.Errorf("quiet mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReason, reason); diff != "" {
+				t.Errorf("reason mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClockTimeInvalid(t *testing.T) {
+	var c ClockTime
+	if err := c.UnmarshalText([]byte("7pm")); err == nil {
+		t.Error("expected an error")
+	}
+}
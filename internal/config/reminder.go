@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReminderKind is how a reminder is shown.
+type ReminderKind string
+
+const (
+	// ReminderNotify shows a passive notification banner
+	ReminderNotify ReminderKind = "notify"
+	// ReminderDialog shows the modal join dialog
+	ReminderDialog ReminderKind = "dialog"
+	// ReminderEscalate shows the join dialog again unless the meeting was joined from ooi
+	ReminderEscalate ReminderKind = "escalate"
+)
+
+func (k ReminderKind) valid() bool {
+	return k == ReminderNotify || k == ReminderDialog || k == ReminderEscalate
+}
+
+// Reminder is one stage of the reminder schedule of a meeting.
+type Reminder struct {
+	Name string `toml:"name"`
+	// At is relative to the start time: "-10m" is ten minutes before, "2m" two minutes after
+	At   Duration     `toml:"at"`
+	Kind ReminderKind `toml:"kind"`
+}
+
+// Key identifies the reminder among the reminders of a meeting.
+func (r Reminder) Key() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s %s", r.Kind, r.At.Duration)
+}
+
+// DefaultReminders shows the join dialog one minute before the start.
+func DefaultReminders() []Reminder {
+	return []Reminder{{Name: "join", At: Duration{-time.Minute}, Kind: ReminderDialog}}
+}
+
+// ValidateReminders checks the kinds of a reminder schedule and that each reminder is distinct.
+func ValidateReminders(reminders []Reminder) error {
+	seen := make(map[string]bool)
+	for i, r := range reminders {
+		if !r.Kind.valid() {
+			return fmt.Errorf("reminder %d: kind must be %q, %q or %q, got %q", i+1, ReminderNotify, ReminderDialog, ReminderEscalate, r.Kind)
+		}
+		if seen[r.Key()] {
+			return fmt.Errorf("reminder %d: duplicate reminder %q", i+1, r.Key())
+		}
+		seen[r.Key()] = true
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReminders(t *testing.T) {
+	cfg := Default()
+	data := `
+[[reminder]]
+name = "heads-up"
+at = "-10m"
+kind = "notify"
+
+[[reminder]]
+at = "2m"
+kind = "escalate"
+`
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := []Reminder{
+		{Name: "heads-up", At: Duration{-10 * time.Minute}, Kind: ReminderNotify},
+		{At: Duration{2 * time.Minute}, Kind: ReminderEscalate},
+	}
+	if diff := cmp.Diff(want, cfg.Reminders); diff != "" {
+		t.Errorf("reminders mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("escalate 2m0s", cfg.Reminders[1].Key()); diff != "" {
+		t.Errorf("key mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateReminders(t *testing.T) {
+	tests := []struct {
+		name      string
+		reminders []Reminder
+		wantErr   bool
+	}{
+		{name: "default", reminders: DefaultReminders()},
+		{name: "empty", reminders: nil},
+		{name: "invalid kind", reminders: []Reminder{{At: Duration{-time.Minute}, Kind: "popup"}}, wantErr: true},
+		{
+			name: "duplicate",
+			reminders: []Reminder{
+				{At: Duration{-time.Minute}, Kind: ReminderDialog},
+				{At: Duration{-time.Minute}, Kind: ReminderDialog},
+			},
+			wantErr: true,
+		},
+		{
+			name: "same time with different names",
+			reminders: []Reminder{
+				{Name: "a", At: Duration{-time.Minute}, Kind: ReminderNotify},
+				{Name: "b", At: Duration{-time.Minute}, Kind: ReminderDialog},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReminders(tt.reminders)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReminders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/knwoop/ooi/internal/calendar"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase of the encrypted-file token store.
+const PassphraseEnv = "OOI_TOKEN_PASSPHRASE"
+
+type TokenBackend string
+
+const (
+	// BackendAuto uses the OS keyring when available, then an encrypted file if a
+	// passphrase or key file is set, then a plaintext file
+	BackendAuto          TokenBackend = "auto"
+	BackendKeychain      TokenBackend = "keychain"
+	BackendSecretService TokenBackend = "secret-service"
+	BackendEncryptedFile TokenBackend = "encrypted-file"
+	BackendFile          TokenBackend = "file"
+)
+
+func (b TokenBackend) valid() bool {
+	switch b {
+	case BackendAuto, BackendKeychain, BackendSecretService, BackendEncryptedFile, BackendFile:
+		return true
+	}
+	return false
+}
+
+// TokenStoreConfig selects where OAuth tokens are stored.
+type TokenStoreConfig struct {
+	Backend TokenBackend `toml:"backend"`
+	// KeyFile is a file whose contents encrypt the encrypted-file store.
+	// Without it, the passphrase is read from OOI_TOKEN_PASSPHRASE.
+	KeyFile string `toml:"key_file"`
+}
+
+// ResolvedBackend returns the backend to use, resolving auto for this machine.
+func (c TokenStoreConfig) ResolvedBackend() TokenBackend {
+	if c.Backend != BackendAuto && c.Backend != "" {
+		return c.Backend
+	}
+	if runtime.GOOS == "darwin" {
+		return BackendKeychain
+	}
+	if _, err := exec.LookPath("secret-tool"); err == nil {
+		return BackendSecretService
+	}
+	if c.KeyFile != "" || os.Getenv(PassphraseEnv) != "" {
+		return BackendEncryptedFile
+	}
+	return BackendFile
+}
+
+// Open returns the configured token store. Tokens still in plaintext files are
+// moved into it the first time they are loaded.
+func (c TokenStoreConfig) Open() (calendar.TokenStore, error) {
+	store, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+	return calendar.WithMigration(store)
+}
+
+func (c TokenStoreConfig) open() (calendar.TokenStore, error) {
+	switch backend := c.ResolvedBackend(); backend {
+	case BackendKeychain:
+		return calendar.NewKeychainStore(), nil
+	case BackendSecretService:
+		return calendar.NewSecretServiceStore(), nil
+	case BackendEncryptedFile:
+		secret, err := c.secret()
+		if err != nil {
+			return nil, err
+		}
+		configDir, err := calendar.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		return calendar.NewEncryptedFileStore(configDir, secret)
+	case BackendFile:
+		return calendar.NewFileTokenStore()
+	default:
+		return nil, fmt.Errorf("unknown token store backend %q", backend)
+	}
+}
+
+func (c TokenStoreConfig) secret() ([]byte, error) {
+	if c.KeyFile == "" {
+		return []byte(os.Getenv(PassphraseEnv)), nil
+	}
+	data, err := os.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token key file: %w", err)
+	}
+	return bytes.TrimSpace(data), nil
+}
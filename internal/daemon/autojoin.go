@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+)
+
+// checkAutoJoin schedules the meetings selected by the auto_join rules and joins
+// those whose time has come. A notice is shown CancelWindow before joining.
+func (s *Scheduler) checkAutoJoin() {
+	now := s.now()
+	lead := s.config.AutoJoin.Lead.Duration
+	window := s.config.AutoJoin.CancelWindow.Duration
+
+	s.cacheMu.RLock()
+	events := s.cachedEvents
+	s.cacheMu.RUnlock()
+
+	s.joinMu.Lock()
+	for _, event := range events {
+		key := eventKey{eventID: event.ID, startTime: event.StartTime}
+		if s.autoJoined[key] {
+			continue
+		}
+		joinAt := event.StartTime.Add(-lead)
+		if now.Before(joinAt.Add(-window)) || !now.Before(event.EndTime) {
+			continue
+		}
+
+		decision := s.rules.EvaluateAutoJoin(event)
+		if !decision.Included {
+			continue
+		}
+		s.autoJoined[key] = true
+
+		if action, reason := s.alertAction(event.StartTime); action != config.ActionAlert {
+			log.Printf("Not auto-joining %s: %s", event.Title, reason)
+			continue
+		}
+
+		if joinAt.Before(now) {
+			joinAt = now
+		}
+		log.Printf("Auto-joining %s at %s %s", event.Title, joinAt.Format("15:04:05"), decision)
+		s.pendingJoins = append(s.pendingJoins, pendingJoin{event: event, at: joinAt})
+
+		message := fmt.Sprintf("Joining %s in %s. Choose Cancel auto-join in the menu bar to stay out.", event.Provider.DisplayName(), joinAt.Sub(now).Round(time.Second))
+		if err := s.notifier.ShowNotification(event.Title, message); err != nil {
+			log.Printf("Failed to show notification: %v", err)
+		}
+	}
+
+	var remaining, due []pendingJoin
+	for _, join := range s.pendingJoins {
+		if now.Before(join.at) {
+			remaining = append(remaining, join)
+			continue
+		}
+		due = append(due, join)
+	}
+	s.pendingJoins = remaining
+
+	// Forget meetings that ended long ago
+	for key := range s.autoJoined {
+		if key.startTime.Before(now.Add(-24 * time.Hour)) {
+			delete(s.autoJoined, key)
+		}
+	}
+	for key := range s.joined {
+		if key.startTime.Before(now.Add(-24 * time.Hour)) {
+			delete(s.joined, key)
+		}
+	}
+	s.joinMu.Unlock()
+
+	// OpenMeeting takes joinMu to record the join
+	for _, join := range due {
+		log.Printf("Auto-joined %s", join.event.Title)
+		s.OpenMeeting(&join.event)
+	}
+}
+
+// PendingAutoJoin returns the meeting that is about to be joined automatically, or nil.
+func (s *Scheduler) PendingAutoJoin() *calendar.Event {
+	s.joinMu.Lock()
+	defer s.joinMu.Unlock()
+
+	if len(s.pendingJoins) == 0 {
+		return nil
+	}
+	event := s.pendingJoins[0].event
+	return &event
+}
+
+// CancelAutoJoin cancels the pending automatic joins.
+func (s *Scheduler) CancelAutoJoin() {
+	s.joinMu.Lock()
+	defer s.joinMu.Unlock()
+
+	for _, join := range s.pendingJoins {
+		log.Printf("Cancelled auto-join of %s", join.event.Title)
+	}
+	s.pendingJoins = nil
+}
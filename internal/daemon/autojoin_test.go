@@ -0,0 +1,166 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/filter"
+	"github.com/knwoop/ooi/internal/notifier"
+)
+
+// fakeNotifier records what would be shown and opened. Alerts return the
+// queued results in order, then close the dialog.
+type fakeNotifier struct {
+	alerts        [][]notifier.Meeting
+	notifications []string
+	opened        []string
+	results       []notifier.AlertResult
+}
+
+func (f *fakeNotifier) ShowMeetingAlert(meetings []notifier.Meeting) (notifier.AlertResult, error) {
+	f.alerts = append(f.alerts, meetings)
+	if len(f.results) == 0 {
+		return notifier.AlertResult{Action: notifier.AlertClosed, Index: -1}, nil
+	}
+	result := f.results[0]
+	f.results = f.results[1:]
+	return result, nil
+}
+
+func (f *fakeNotifier) ShowNotification(title, message string) error {
+	f.notifications = append(f.notifications, title)
+	return nil
+}
+
+func (f *fakeNotifier) ShowAuthErrorAlert(message string) error {
+	return nil
+}
+
+func (f *fakeNotifier) OpenMeetLink(link string, nativeApp bool) error {
+	f.opened = append(f.opened, link)
+	return nil
+}
+
+func TestAutoJoin(t *testing.T) {
+	rules, err := filter.Parse(`
+[auto_join]
+
+[[auto_join.rule]]
+action = "include"
+title = "Standup"
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.AutoJoin.Lead = config.Duration{Duration: 30 * time.Second}
+	cfg.AutoJoin.CancelWindow = config.Duration{Duration: 10 * time.Second}
+
+	fake := &fakeNotifier{}
+	now := start.Add(-2 * time.Minute)
+	s := NewScheduler(&fakeSource{}, cfg, rules)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "standup", Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute), JoinURL: "https://meet.google.com/standup"},
+		{ID: "review", Title: "Review", StartTime: start, EndTime: start.Add(time.Hour), JoinURL: "https://meet.google.com/review"},
+	}
+
+	step := func(to time.Time) {
+		now = to
+		s.checkAlerts()
+		s.checkAutoJoin()
+	}
+
+	// The regular alert shows only the meeting that is not auto-joined
+	step(start.Add(-time.Minute))
+	if diff := cmp.Diff([][]notifier.Meeting{{{Title: "Review", JoinURL: "https://meet.google.com/review"}}}, fake.alerts); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+
+	// The notice is shown when the cancel window opens
+	step(start.Add(-40 * time.Second))
+	if diff := cmp.Diff([]string{"Standup"}, fake.notifications); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+	if got := s.PendingAutoJoin(); got == nil || got.ID != "standup" {
+		t.Errorf("expected standup to be pending, got %v", got)
+	}
+
+	step(start.Add(-31 * time.Second))
+	if len(fake.opened) != 0 {
+		t.Errorf("joined before the lead time: %v", fake.opened)
+	}
+
+	step(start.Add(-30 * time.Second))
+	if diff := cmp.Diff([]string{"https://meet.google.com/standup"}, fake.opened); diff != "" {
+		t.Errorf("opened links mismatch (-want +got):\n%s", diff)
+	}
+
+	// Each meeting is joined once
+	step(start)
+	if diff := cmp.Diff(1, len(fake.opened)); diff != "" {
+		t.Errorf("open count mismatch (-want +got):\n%s", diff)
+	}
+	if s.PendingAutoJoin() != nil {
+		t.Error("expected no pending auto-join")
+	}
+}
+
+func TestAutoJoinCancel(t *testing.T) {
+	rules, err := filter.Parse("[auto_join]\ndefault = \"include\"\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	fake := &fakeNotifier{}
+	now := start.Add(-5 * time.Second)
+	s := NewScheduler(&fakeSource{}, config.Default(), rules)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "sync", Title: "Sync", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/sync"},
+	}
+
+	s.checkAutoJoin()
+	s.CancelAutoJoin()
+
+	now = start.Add(time.Minute)
+	s.checkAutoJoin()
+	if len(fake.opened) != 0 {
+		t.Errorf("joined a cancelled meeting: %v", fake.opened)
+	}
+}
+
+func TestAutoJoinRespectsQuietHours(t *testing.T) {
+	rules, err := filter.Parse("[auto_join]\ndefault = \"include\"\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// A Saturday
+	start := time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.DisplayTimeZone = "UTC"
+	cfg.QuietHours.Weekends = true
+
+	fake := &fakeNotifier{}
+	now := start
+	s := NewScheduler(&fakeSource{}, cfg, rules)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "game", Title: "Game night", StartTime: start, EndTime: start.Add(time.Hour), JoinURL: "https://meet.google.com/game"},
+	}
+
+	s.checkAutoJoin()
+	if len(fake.opened) != 0 || len(fake.notifications) != 0 {
+		t.Errorf("auto-joined during quiet hours: opened %v, notified %v", fake.opened, fake.notifications)
+	}
+}
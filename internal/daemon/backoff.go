@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	retryBaseDelay = 15 * time.Second
+	// maxRetryDelay caps the backoff. Regular fetches pause while a retry is
+	// pending, so it may exceed fetch_interval to poll less while failing.
+	maxRetryDelay = 30 * time.Minute
+	maxRetryAfter = time.Hour
+)
+
+// backoff computes exponentially growing retry delays with jitter.
+type backoff struct {
+	base    time.Duration
+	max     time.Duration
+	attempt int
+	jitter  func() float64 // returns a value in [0, 1)
+}
+
+func newBackoff(base, max time.Duration) *backoff {
+	return &backoff{base: base, max: max, jitter: rand.Float64}
+}
+
+// next returns the delay before the next retry. The delay doubles on every call
+// up to max, and half of it is randomized so clients do not retry in lockstep.
+// A longer delay requested by the server via Retry-After takes precedence.
+func (b *backoff) next(retryAfter time.Duration) time.Duration {
+	d := b.base << b.attempt
+	if d <= 0 || d > b.max {
+		d = b.max
+	} else {
+		b.attempt++
+	}
+	d = d/2 + time.Duration(b.jitter()*float64(d/2))
+
+	if retryAfter > d {
+		d = min(retryAfter, maxRetryAfter)
+	}
+	return d
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+)
+
+// StaleAfter is how old cached events may get before they are shown as stale.
+const StaleAfter = 15 * time.Minute
+
+// Cache is the last successful fetch, persisted so alerts work when starting offline.
+type Cache struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	// Accounts are the Google accounts configured at fetch time; "" is the default account
+	Accounts    []string          `json:"accounts"`
+	Events      []calendar.Event  `json:"events"`
+	OutOfOffice []calendar.Period `json:"out_of_office"`
+}
+
+// Stale reports whether the cache is older than StaleAfter at now.
+func (c *Cache) Stale(now time.Time) bool {
+	return now.Sub(c.FetchedAt) > StaleAfter
+}
+
+func CachePath() (string, error) {
+	configDir, err := calendar.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "events-cache.json"), nil
+}
+
+// LoadCache reads the cache for the configured accounts. It returns nil if there
+// is no cache or it was written for a different set of accounts.
+func LoadCache(cfg *config.Config) (*Cache, error) {
+	path, err := CachePath()
+	if err != nil {
+		return nil, err
+	}
+	return loadCacheFile(path, cfg)
+}
+
+func loadCacheFile(path string, cfg *config.Config) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var cache Cache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to decode cache: %w", err)
+	}
+	if !slices.Equal(cache.Accounts, configuredAccounts(cfg)) {
+		return nil, nil
+	}
+	return &cache, nil
+}
+
+// saveCacheFile writes the cache atomically so a crash never leaves a truncated file.
+func saveCacheFile(path string, cache *Cache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".events-cache-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache: %w", err)
+	}
+	return nil
+}
+
+func configuredAccounts(cfg *config.Config) []string {
+	accounts := []string{""}
+	for _, account := range cfg.Accounts {
+		accounts = append(accounts, account.Name)
+	}
+	return accounts
+}
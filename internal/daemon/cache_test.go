@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+)
+
+type failingSource struct{}
+
+func (failingSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	return nil, errors.New("network is unreachable")
+}
+
+func TestSchedulerStartsFromCacheWhenOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events-cache.json")
+	fetchedAt := time.Now().Truncate(time.Second)
+	start := fetchedAt.Add(30 * time.Minute)
+
+	online := NewScheduler(&fakeSource{
+		events: []calendar.Event{{ID: "standup", Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute)}},
+	}, nil, nil)
+	online.cachePath = path
+	online.now = func() time.Time { return fetchedAt }
+	online.fetchEvents(context.Background())
+
+	// The next boot has no network
+	offline := NewScheduler(failingSource{}, nil, nil)
+	offline.cachePath = path
+	offline.loadCache()
+	offline.fetchEvents(context.Background())
+
+	// Alerts still fire from the cached events
+	var got []string
+	due, _ := offline.dueReminders(start.Add(-30 * time.Second))
+	for _, d := range due {
+		got = append(got, d.event.ID)
+	}
+	if diff := cmp.Diff([]string{"standup"}, got); diff != "" {
+		t.Errorf("due events mismatch (-want +got):\n%s", diff)
+	}
+
+	offline.now = func() time.Time { return fetchedAt.Add(5 * time.Minute) }
+	if _, stale := offline.Stale(); stale {
+		t.Error("cache should be fresh after 5 minutes")
+	}
+	offline.now = func() time.Time { return fetchedAt.Add(time.Hour) }
+	gotFetchedAt, stale := offline.Stale()
+	if !stale {
+		t.Error("cache should be stale after an hour")
+	}
+	if !gotFetchedAt.Equal(fetchedAt) {
+		t.Errorf("fetchedAt = %v, want %v", gotFetchedAt, fetchedAt)
+	}
+}
+
+func TestLoadCacheIgnoresOtherAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events-cache.json")
+	cache := &Cache{
+		FetchedAt: time.Now(),
+		Accounts:  []string{""},
+		Events:    []calendar.Event{{ID: "standup"}},
+	}
+	if err := saveCacheFile(path, cache); err != nil {
+		t.Fatalf("saveCacheFile failed: %v", err)
+	}
+
+	got, err := loadCacheFile(path, config.Default())
+	if err != nil {
+		t.Fatalf("loadCacheFile failed: %v", err)
+	}
+	if got == nil || len(got.Events) != 1 {
+		t.Fatalf("expected the cached event, got %+v", got)
+	}
+
+	cfg := config.Default()
+	cfg.Accounts = []config.AccountConfig{{Name: "work"}}
+	got, err = loadCacheFile(path, cfg)
+	if err != nil {
+		t.Fatalf("loadCacheFile failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected no cache for different accounts, got %+v", got)
+	}
+}
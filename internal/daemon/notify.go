@@ -0,0 +1,30 @@
+package daemon
+
+import "github.com/knwoop/ooi/internal/notifier"
+
+// Notifier shows alerts and opens meetings. Tests replace it with a fake.
+type Notifier interface {
+	ShowMeetingAlert(meetings []notifier.Meeting) (notifier.AlertResult, error)
+	ShowNotification(title, message string) error
+	ShowAuthErrorAlert(message string) error
+	OpenMeetLink(link string, nativeApp bool) error
+}
+
+// systemNotifier uses macOS dialogs and notifications.
+type systemNotifier struct{}
+
+func (systemNotifier) ShowMeetingAlert(meetings []notifier.Meeting) (notifier.AlertResult, error) {
+	return notifier.ShowMeetingAlert(meetings)
+}
+
+func (systemNotifier) ShowNotification(title, message string) error {
+	return notifier.ShowNotification(title, message)
+}
+
+func (systemNotifier) ShowAuthErrorAlert(message string) error {
+	return notifier.ShowAuthErrorAlert(message)
+}
+
+func (systemNotifier) OpenMeetLink(link string, nativeApp bool) error {
+	return notifier.OpenMeetLink(link, nativeApp)
+}
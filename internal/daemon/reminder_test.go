@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/filter"
+	"github.com/knwoop/ooi/internal/notifier"
+)
+
+func TestReminderSchedule(t *testing.T) {
+	rules, err := filter.Parse(`
+[[rule]]
+action = "include"
+title = "Interview"
+
+[[rule.reminder]]
+name = "prep"
+at = "-30m"
+kind = "notify"
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.Reminders = []config.Reminder{
+		{Name: "heads-up", At: config.Duration{Duration: -10 * time.Minute}, Kind: config.ReminderNotify},
+		{Name: "join", At: config.Duration{Duration: -time.Minute}, Kind: config.ReminderDialog},
+		{Name: "late", At: config.Duration{Duration: 2 * time.Minute}, Kind: config.ReminderEscalate},
+	}
+
+	fake := &fakeNotifier{}
+	now := start.Add(-time.Hour)
+	s := NewScheduler(&fakeSource{}, cfg, rules)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "sync", Title: "Sync", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/sync"},
+		{ID: "review", Title: "Review", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/review"},
+		{ID: "interview", Title: "Interview", StartTime: start, EndTime: start.Add(time.Hour), JoinURL: "https://meet.google.com/interview"},
+	}
+
+	step := func(to time.Time) {
+		now = to
+		s.checkAlerts()
+	}
+
+	// The rule replaces the schedule of the interview
+	step(start.Add(-30 * time.Minute))
+	step(start.Add(-10 * time.Minute))
+	if diff := cmp.Diff([]string{"Interview", "Sync", "Review"}, fake.notifications); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	step(start.Add(-time.Minute))
+	want := [][]notifier.Meeting{{
+		{Title: "Sync", JoinURL: "https://meet.google.com/sync"},
+		{Title: "Review", JoinURL: "https://meet.google.com/review"},
+	}}
+	if diff := cmp.Diff(want, fake.alerts); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+
+	// Joined meetings are not escalated
+	s.OpenMeeting(&s.cachedEvents[0])
+	step(start.Add(2 * time.Minute))
+	want = append(want, []notifier.Meeting{{Title: "Review (started 2 min ago)", JoinURL: "https://meet.google.com/review"}})
+	if diff := cmp.Diff(want, fake.alerts); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+
+	// Each reminder is shown once
+	step(start.Add(3 * time.Minute))
+	if diff := cmp.Diff(2, len(fake.alerts)); diff != "" {
+		t.Errorf("alert count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReminderAfterSleep(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.Reminders = []config.Reminder{
+		{At: config.Duration{Duration: -10 * time.Minute}, Kind: config.ReminderNotify},
+		{At: config.Duration{Duration: -time.Minute}, Kind: config.ReminderDialog},
+	}
+
+	fake := &fakeNotifier{}
+	now := start.Add(30 * time.Second)
+	s := NewScheduler(&fakeSource{}, cfg, nil)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "sync", Title: "Sync", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/sync"},
+	}
+
+	// Only the latest of the missed reminders is shown
+	s.checkAlerts()
+	s.checkAlerts()
+	if len(fake.notifications) != 0 {
+		t.Errorf("showed a passed reminder: %v", fake.notifications)
+	}
+	if diff := cmp.Diff(1, len(fake.alerts)); diff != "" {
+		t.Errorf("alert count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSnoozeAndDismiss(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.Reminders = []config.Reminder{
+		{Name: "join", At: config.Duration{Duration: -time.Minute}, Kind: config.ReminderDialog},
+		{Name: "late", At: config.Duration{Duration: 2 * time.Minute}, Kind: config.ReminderEscalate},
+		{Name: "later", At: config.Duration{Duration: 6 * time.Minute}, Kind: config.ReminderEscalate},
+	}
+
+	fake := &fakeNotifier{results: []notifier.AlertResult{
+		{Action: notifier.AlertSnooze, Index: -1, Snooze: 5 * time.Minute},
+		{Action: notifier.AlertDismiss, Index: 1},
+	}}
+	now := start.Add(-time.Minute)
+	s := NewScheduler(&fakeSource{}, cfg, nil)
+	s.notifier = fake
+	s.now = func() time.Time { return now }
+	s.cachedEvents = []calendar.Event{
+		{ID: "sync", Title: "Sync", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/sync"},
+		{ID: "review", Title: "Review", StartTime: start, EndTime: start.Add(30 * time.Minute), JoinURL: "https://meet.google.com/review"},
+	}
+
+	step := func(to time.Time) {
+		now = to
+		s.checkAlerts()
+	}
+
+	// Both meetings are snoozed; the escalation in the meantime is replaced by the snooze
+	step(start.Add(-time.Minute))
+	step(start.Add(2 * time.Minute))
+	if diff := cmp.Diff(1, len(fake.alerts)); diff != "" {
+		t.Errorf("alert count mismatch (-want +got):\n%s", diff)
+	}
+
+	// Review is dismissed when the snooze is over
+	step(start.Add(4 * time.Minute))
+	if diff := cmp.Diff(2, len(fake.alerts)); diff != "" {
+		t.Errorf("alert count mismatch (-want +got):\n%s", diff)
+	}
+
+	// Sync was only closed, so it is escalated; Review is not mentioned again
+	step(start.Add(6 * time.Minute))
+	want := []notifier.Meeting{{Title: "Sync (started 6 min ago)", JoinURL: "https://meet.google.com/sync"}}
+	if diff := cmp.Diff(want, fake.alerts[len(fake.alerts)-1]); diff != "" {
+		t.Errorf("alert mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -8,45 +8,85 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/filter"
 	"github.com/knwoop/ooi/internal/notifier"
-	"google.golang.org/api/googleapi"
 )
 
-const (
-	fetchInterval  = 3 * time.Minute
-	alertInterval  = 1 * time.Second
-	notifyBefore   = 1 * time.Minute
-	missedLookback = 1 * time.Hour // Look back for missed meetings
-)
+const alertInterval = 1 * time.Second
+
+// maxFetchTimeout bounds a fetch so that a server that never answers does not
+// block alerts, which run on the same loop.
+const maxFetchTimeout = time.Minute
 
 type eventKey struct {
 	eventID   string
 	startTime time.Time
+	reminder  string // Key of the reminder; empty when the key identifies the meeting
 }
 
 type Scheduler struct {
-	client         *calendar.Client
+	source         calendar.EventSource
+	config         *config.Config
+	rules          *filter.Rules
+	decisions      map[string]string // Rule decision per event ID, to log changes only
 	cachedEvents   []calendar.Event
+	outOfOffice    []calendar.Period
+	fetchedAt      time.Time
 	cacheMu        sync.RWMutex
+	cachePath      string // Where the last fetch is persisted; empty disables the cache
 	notifiedEvents map[eventKey]bool
-	authErrorShown bool
+	snoozed        map[eventKey]time.Time // When to alert again for snoozed meetings
+	dismissed      map[eventKey]bool      // Meetings not to remind of any more
+	authErrorShown map[string]bool        // Auth alerts shown since the last successful fetch
+	backoff        *backoff
+	retryAt        time.Time // When to retry a failed fetch before the next regular one
+	now            func() time.Time
+	notifier       Notifier
+	syncCh         chan struct{} // Sync requests handled by Run
+
+	joinMu       sync.Mutex
+	autoJoined   map[eventKey]bool // Meetings auto-joined, scheduled or skipped
+	joined       map[eventKey]bool // Meetings joined from ooi, which are not escalated
+	pendingJoins []pendingJoin
+}
+
+// pendingJoin is a meeting that is joined at the given time unless cancelled.
+type pendingJoin struct {
+	event calendar.Event
+	at    time.Time
 }
 
-func NewScheduler(client *calendar.Client) *Scheduler {
+// NewScheduler creates a scheduler. A nil cfg uses the defaults and nil rules include every event.
+func NewScheduler(source calendar.EventSource, cfg *config.Config, rules *filter.Rules) *Scheduler {
+	if cfg == nil {
+		cfg = config.Default()
+	}
 	return &Scheduler{
-		client:         client,
+		source:         source,
+		config:         cfg,
+		rules:          rules,
 		notifiedEvents: make(map[eventKey]bool),
+		snoozed:        make(map[eventKey]time.Time),
+		dismissed:      make(map[eventKey]bool),
+		backoff:        newBackoff(retryBaseDelay, maxRetryDelay),
+		now:            time.Now,
+		notifier:       systemNotifier{},
+		syncCh:         make(chan struct{}, 1),
+		autoJoined:     make(map[eventKey]bool),
+		joined:         make(map[eventKey]bool),
 	}
 }
 
 func (s *Scheduler) Run(ctx context.Context) error {
-	log.Printf("Scheduler started (fetch: %v, alert check: %v)", fetchInterval, alertInterval)
+	log.Printf("Scheduler started (fetch: %v, alert check: %v)", s.config.FetchInterval.Duration, alertInterval)
 
 	// Write PID file
 	if err := writePIDFile(); err != nil {
@@ -54,6 +94,15 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	}
 	defer removePIDFile()
 
+	if s.cachePath == "" {
+		if path, err := CachePath(); err == nil {
+			s.cachePath = path
+		}
+	}
+
+	// Alert from the last fetch until the network is reachable
+	s.loadCache()
+
 	// Initial fetch
 	s.fetchEvents(ctx)
 
@@ -61,7 +110,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGUSR1)
 
-	fetchTicker := time.NewTicker(fetchInterval)
+	fetchTicker := time.NewTicker(s.config.FetchInterval.Duration)
 	alertTicker := time.NewTicker(alertInterval)
 	defer fetchTicker.Stop()
 	defer alertTicker.Stop()
@@ -74,95 +123,442 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		case <-sigCh:
 			log.Println("Received SIGUSR1, syncing...")
 			s.fetchEvents(ctx)
-		case <-fetchTicker.C:
+		case <-s.syncCh:
+			log.Println("Sync requested from the menu bar")
 			s.fetchEvents(ctx)
+		case <-fetchTicker.C:
+			s.onFetchTick(ctx)
 		case <-alertTicker.C:
-			s.checkAlerts()
+			s.onAlertTick(ctx)
 		}
 	}
 }
 
+// onFetchTick runs the regular fetch, unless a retry after a failure is pending:
+// then the fetch waits for the retry so backoff and Retry-After are honored.
+func (s *Scheduler) onFetchTick(ctx context.Context) {
+	if !s.retryAt.IsZero() {
+		return
+	}
+	s.fetchEvents(ctx)
+}
+
+// onAlertTick runs a pending retry when it is due and shows due alerts.
+func (s *Scheduler) onAlertTick(ctx context.Context) {
+	if !s.retryAt.IsZero() && !s.now().Before(s.retryAt) {
+		s.retryAt = time.Time{}
+		s.fetchEvents(ctx)
+	}
+	s.checkAlerts()
+	s.checkAutoJoin()
+}
+
 func (s *Scheduler) fetchEvents(ctx context.Context) {
-	// Fetch events from past (for missed meetings) to end of today
-	now := time.Now()
-	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
-	lookAhead := endOfDay.Sub(now)
-	if lookAhead < time.Minute {
-		lookAhead = time.Minute
-	}
-	events, err := s.client.GetEventsInRange(ctx, missedLookback, lookAhead)
-	if err != nil {
-		log.Printf("Failed to fetch events: %v", err)
-		if isAuthError(err) && !s.authErrorShown {
-			log.Println("Auth error detected, showing alert")
-			if alertErr := notifier.ShowAuthErrorAlert(); alertErr != nil {
-				log.Printf("Failed to show auth error alert: %v", alertErr)
-			}
-			s.authErrorShown = true
+	ctx, cancel := context.WithTimeout(ctx, min(s.config.FetchInterval.Duration, maxFetchTimeout))
+	defer cancel()
+
+	// Fetch events from past (for missed meetings) over a rolling horizon,
+	// so meetings early tomorrow are known before midnight
+	events, err := s.source.GetEventsInRange(ctx, s.config.MissedLookback.Duration, s.config.Lookahead.Duration)
+	var partial *calendar.PartialError
+	switch {
+	case errors.As(err, &partial):
+		// Keep the events of the calendars that worked and retry the others
+		s.handleFetchError(partial.Err)
+	case err != nil:
+		s.handleFetchError(err)
+		return
+	default:
+		// Reset error state on successful fetch
+		s.authErrorShown = nil
+		s.backoff.reset()
+		s.retryAt = time.Time{}
+	}
+
+	now := s.now()
+	var outOfOffice []calendar.Period
+	if oof, ok := s.source.(calendar.OutOfOfficeSource); ok {
+		outOfOffice = oof.OutOfOffice(now.Add(-s.config.MissedLookback.Duration), now.Add(s.config.Lookahead.Duration))
+	}
+
+	if partial != nil {
+		// Failed calendars keep their last known events. The fetch time and the
+		// cache are left as they were, so the events still show as stale.
+		s.cacheMu.RLock()
+		previous, previousOutOfOffice, fetchedAt := s.cachedEvents, s.outOfOffice, s.fetchedAt
+		s.cacheMu.RUnlock()
+		if len(outOfOffice) == 0 {
+			outOfOffice = previousOutOfOffice
 		}
+		events = calendar.KeepMissingCalendars(events, previous)
+		s.setEvents(events, outOfOffice, fetchedAt)
+		log.Printf("Fetched %d events, some calendars failed", len(events))
 		return
 	}
 
-	// Reset auth error flag on successful fetch
-	s.authErrorShown = false
+	s.setEvents(events, outOfOffice, now)
+	log.Printf("Fetched %d events", len(events))
+
+	if s.cachePath != "" {
+		cache := &Cache{
+			FetchedAt:   now,
+			Accounts:    configuredAccounts(s.config),
+			Events:      events,
+			OutOfOffice: outOfOffice,
+		}
+		if err := saveCacheFile(s.cachePath, cache); err != nil {
+			log.Printf("Failed to save event cache: %v", err)
+		}
+	}
+}
+
+// setEvents filters fetched events and replaces the cached ones.
+func (s *Scheduler) setEvents(events []calendar.Event, outOfOffice []calendar.Period, fetchedAt time.Time) {
+	if !s.config.AlertAllDay {
+		events = calendar.ExcludeAllDay(events)
+	}
+	events = s.applyRules(events)
 
 	s.cacheMu.Lock()
 	s.cachedEvents = events
+	s.outOfOffice = outOfOffice
+	s.fetchedAt = fetchedAt
 	s.cacheMu.Unlock()
+}
 
-	log.Printf("Fetched %d events", len(events))
+// loadCache restores the events persisted by the last successful fetch.
+func (s *Scheduler) loadCache() {
+	if s.cachePath == "" {
+		return
+	}
+	cache, err := loadCacheFile(s.cachePath, s.config)
+	if err != nil {
+		log.Printf("Failed to load event cache: %v", err)
+		return
+	}
+	if cache == nil {
+		return
+	}
+	log.Printf("Loaded %d cached events fetched at %s", len(cache.Events), cache.FetchedAt.Format(time.RFC3339))
+	s.setEvents(cache.Events, cache.OutOfOffice, cache.FetchedAt)
 }
 
-func isAuthError(err error) bool {
-	var gErr *googleapi.Error
-	if errors.As(err, &gErr) {
-		return gErr.Code == 401 || gErr.Code == 403
+// handleFetchError reacts to the kind of fetch failure: auth problems are shown
+// to the user once, and temporary failures are retried with backoff. Errors of
+// several calendars, joined with errors.Join, are handled one by one.
+func (s *Scheduler) handleFetchError(err error) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var retryErr error
+	for _, err := range errs {
+		kind := calendar.Classify(err)
+		log.Printf("Failed to fetch events (%s): %v", kind, err)
+
+		switch {
+		case kind == calendar.KindAuthRevoked || kind == calendar.KindTokenExpired:
+			message := authErrorMessage(kind, err)
+			if s.authErrorShown[message] {
+				continue
+			}
+			log.Println("Auth error detected, showing alert")
+			if alertErr := s.notifier.ShowAuthErrorAlert(message); alertErr != nil {
+				log.Printf("Failed to show auth error alert: %v", alertErr)
+			}
+			if s.authErrorShown == nil {
+				s.authErrorShown = make(map[string]bool)
+			}
+			s.authErrorShown[message] = true
+		case kind.Retryable() && retryErr == nil:
+			retryErr = err
+		}
+	}
+
+	if retryErr != nil {
+		delay := s.backoff.next(calendar.RetryAfter(retryErr))
+		s.retryAt = s.now().Add(delay)
+		log.Printf("Retrying in %s", delay.Round(time.Second))
 	}
-	return false
 }
 
-func (s *Scheduler) checkAlerts() {
+// authErrorMessage tells the user how to fix an auth error of the source that failed.
+func authErrorMessage(kind calendar.ErrorKind, err error) string {
+	var sourceErr *calendar.SourceError
+	if !errors.As(err, &sourceErr) {
+		if kind == calendar.KindAuthRevoked {
+			return "Calendar access was revoked. Please run 'ooi auth' to authorize ooi again."
+		}
+		return "Session expired. Please run 'ooi auth' to re-authenticate."
+	}
+
+	if sourceErr.AuthCommand == "" {
+		var httpErr *calendar.HTTPError
+		if errors.As(err, &httpErr) {
+			return fmt.Sprintf("%s returned %s.", sourceErr.Source, httpErr.Status)
+		}
+		return fmt.Sprintf("%s could not be fetched: %v", sourceErr.Source, sourceErr.Err)
+	}
+	if kind == calendar.KindAuthRevoked {
+		return fmt.Sprintf("Calendar access for %s was revoked. Please run '%s' to authorize ooi again.", sourceErr.Source, sourceErr.AuthCommand)
+	}
+	return fmt.Sprintf("Session for %s expired. Please run '%s' to re-authenticate.", sourceErr.Source, sourceErr.AuthCommand)
+}
+
+// Stale returns when events were last fetched and whether that is longer ago than StaleAfter.
+func (s *Scheduler) Stale() (time.Time, bool) {
 	s.cacheMu.RLock()
-	events := s.cachedEvents
+	fetchedAt := s.fetchedAt
 	s.cacheMu.RUnlock()
 
-	now := time.Now()
+	if fetchedAt.IsZero() {
+		return fetchedAt, false
+	}
+	return fetchedAt, s.now().Sub(fetchedAt) > StaleAfter
+}
 
-	// Collect all events that need notification
-	var eventsToNotify []calendar.Event
+// applyRules filters events by the rules and logs decisions made by a rule when they change.
+func (s *Scheduler) applyRules(events []calendar.Event) []calendar.Event {
+	decisions := make(map[string]string, len(events))
+	var result []calendar.Event
 	for _, event := range events {
-		key := eventKey{
-			eventID:   event.ID,
-			startTime: event.StartTime,
+		d := s.rules.Evaluate(event)
+		if d.Rule >= 0 {
+			reason := d.String()
+			if s.decisions[event.ID] != reason {
+				log.Printf("Event %q %s", event.Title, reason)
+			}
+			decisions[event.ID] = reason
+		}
+		if d.Included {
+			result = append(result, event)
 		}
+	}
+	s.decisions = decisions
+	return result
+}
 
-		if s.notifiedEvents[key] {
+func (s *Scheduler) checkAlerts() {
+	now := s.now()
+	due, passed := s.dueReminders(now)
+
+	var alerts []calendar.Event
+	for _, d := range due {
+		event := d.event
+		if s.dismissed[meetingKey(event)] {
+			log.Printf("Skipped %s reminder for %s: dismissed", d.reminder.Key(), event.Title)
+			continue
+		}
+		if _, ok := s.snoozed[meetingKey(event)]; ok {
+			// The snoozed alert replaces reminders due in the meantime
 			continue
 		}
+		if d.reminder.Kind == config.ReminderEscalate {
+			if s.hasJoined(event) {
+				continue
+			}
+			event.Title += fmt.Sprintf(" (started %d min ago)", int(now.Sub(event.StartTime).Minutes()))
+		}
 
-		timeUntil := event.StartTime.Sub(now)
+		action, reason := s.alertAction(event.StartTime)
+		switch {
+		case action == config.ActionSuppress:
+			log.Printf("Suppressed %s reminder for %s: %s", d.reminder.Key(), event.Title, reason)
+		case action == config.ActionNotify || d.reminder.Kind == config.ReminderNotify:
+			if action == config.ActionNotify {
+				log.Printf("Downgraded %s reminder for %s to a notification: %s", d.reminder.Key(), event.Title, reason)
+			}
+			if err := s.notifier.ShowNotification(event.Title, "Meeting starting at "+event.StartTime.In(s.config.DisplayLocation()).Format("15:04")); err != nil {
+				log.Printf("Failed to show notification: %v", err)
+			}
+		default:
+			if decision := s.rules.EvaluateAutoJoin(event); decision.Included {
+				log.Printf("Skipped %s reminder for %s: auto-join %s", d.reminder.Key(), event.Title, decision)
+				continue
+			}
+			alerts = append(alerts, event)
+		}
+	}
+	alerts = append(alerts, s.dueSnoozed(now)...)
+
+	if len(alerts) > 0 {
+		s.notifyMultiple(alerts)
+	}
 
-		// Notify if:
-		// 1. Meeting starts within notifyBefore (upcoming)
-		// 2. Meeting started within missedLookback but wasn't notified (missed)
-		if timeUntil <= notifyBefore && timeUntil > -missedLookback {
-			eventsToNotify = append(eventsToNotify, event)
+	// Mark all as notified, including suppressed and skipped ones
+	for _, d := range due {
+		s.notifiedEvents[reminderKey(d.event, d.reminder)] = true
+	}
+	for _, key := range passed {
+		s.notifiedEvents[key] = true
+	}
+	for key := range s.dismissed {
+		if key.startTime.Before(now.Add(-24 * time.Hour)) {
+			delete(s.dismissed, key)
 		}
 	}
 
-	if len(eventsToNotify) > 0 {
-		s.notifyMultiple(eventsToNotify)
-		// Mark all as notified
-		for _, event := range eventsToNotify {
-			key := eventKey{
-				eventID:   event.ID,
-				startTime: event.StartTime,
+	s.cleanupOldEvents(now)
+}
+
+// alertAction decides how to alert for a meeting starting at t, based on
+// out-of-office events and quiet hours, and returns the reason for a downgrade.
+func (s *Scheduler) alertAction(t time.Time) (config.AlertAction, string) {
+	s.cacheMu.RLock()
+	outOfOffice := s.outOfOffice
+	s.cacheMu.RUnlock()
+
+	for _, p := range outOfOffice {
+		if !t.Before(p.Start) && t.Before(p.End) {
+			reason := "out of office"
+			if p.Title != "" {
+				reason += " (" + p.Title + ")"
 			}
-			s.notifiedEvents[key] = true
+			return s.config.OutOfOffice.Action, reason
 		}
 	}
 
-	s.cleanupOldEvents()
+	if window, ok := s.config.QuietHours.Contains(t.In(s.config.DisplayLocation())); ok {
+		return s.config.QuietHours.Action, "quiet hours (" + window + ")"
+	}
+
+	return config.ActionAlert, ""
+}
+
+// SuppressionReason explains why alerts are currently downgraded or suppressed,
+// or returns an empty string if they are not.
+func (s *Scheduler) SuppressionReason() string {
+	action, reason := s.alertAction(s.now())
+	switch action {
+	case config.ActionSuppress:
+		return "Alerts off: " + reason
+	case config.ActionNotify:
+		return "Notifications only: " + reason
+	}
+	return ""
+}
+
+// dueReminder is a reminder of an event that is due.
+type dueReminder struct {
+	event    calendar.Event
+	reminder config.Reminder
+}
+
+// meetingKey identifies a meeting regardless of its reminders.
+func meetingKey(event calendar.Event) eventKey {
+	return eventKey{eventID: event.ID, startTime: event.StartTime}
+}
+
+func reminderKey(event calendar.Event, reminder config.Reminder) eventKey {
+	return eventKey{eventID: event.ID, startTime: event.StartTime, reminder: reminder.Key()}
+}
+
+// dueReminders returns the reminders to show at now. When several reminders of
+// an event are due at once, e.g. after waking from sleep, only the latest is
+// shown; the keys of the earlier ones are returned as passed.
+// Times are compared as absolute instants, so DST transitions do not shift alerts.
+func (s *Scheduler) dueReminders(now time.Time) ([]dueReminder, []eventKey) {
+	s.cacheMu.RLock()
+	events := s.cachedEvents
+	s.cacheMu.RUnlock()
+
+	var due []dueReminder
+	var passed []eventKey
+	for _, event := range events {
+		// Meetings that started more than missed_lookback ago are not announced any more
+		if !now.Before(event.StartTime.Add(s.config.MissedLookback.Duration)) {
+			continue
+		}
+
+		var latest *config.Reminder
+		var latestAt time.Time
+		for _, reminder := range s.reminders(event) {
+			key := reminderKey(event, reminder)
+			if s.notifiedEvents[key] {
+				continue
+			}
+			at := reminderTime(event, reminder)
+			if now.Before(at) {
+				continue
+			}
+			if latest != nil {
+				if at.Before(latestAt) {
+					passed = append(passed, key)
+					continue
+				}
+				passed = append(passed, reminderKey(event, *latest))
+			}
+			latest, latestAt = &reminder, at
+		}
+		if latest != nil {
+			due = append(due, dueReminder{event: event, reminder: *latest})
+		}
+	}
+
+	return due, passed
+}
+
+// dueSnoozed returns the snoozed meetings whose snooze is over and forgets them.
+// Meetings that were cancelled or rescheduled in the meantime are dropped.
+func (s *Scheduler) dueSnoozed(now time.Time) []calendar.Event {
+	if len(s.snoozed) == 0 {
+		return nil
+	}
+
+	s.cacheMu.RLock()
+	events := s.cachedEvents
+	s.cacheMu.RUnlock()
+
+	var due []calendar.Event
+	cached := make(map[eventKey]bool)
+	for _, event := range events {
+		key := meetingKey(event)
+		cached[key] = true
+		if until, ok := s.snoozed[key]; ok && !now.Before(until) {
+			delete(s.snoozed, key)
+			due = append(due, event)
+		}
+	}
+	for key := range s.snoozed {
+		if !cached[key] {
+			delete(s.snoozed, key)
+		}
+	}
+	return due
+}
+
+// reminders returns the reminder schedule of the event: the one set by the
+// matching rule, or the configured one.
+func (s *Scheduler) reminders(event calendar.Event) []config.Reminder {
+	if reminders := s.rules.Evaluate(event).Reminders; len(reminders) > 0 {
+		return reminders
+	}
+	return s.config.Reminders
+}
+
+// reminderTime returns when the reminder of the event is due. Dialogs follow
+// reminders set on the event itself (e.g. ICS VALARMs) if there are any.
+func reminderTime(event calendar.Event, reminder config.Reminder) time.Time {
+	if reminder.Kind == config.ReminderDialog {
+		if lead, ok := eventReminderLead(event); ok {
+			return event.StartTime.Add(-lead)
+		}
+	}
+	return event.StartTime.Add(reminder.At.Duration)
+}
+
+// eventReminderLead returns how long before the start the event's own reminders
+// ask to be alerted. The one closest to the start is used since the dialog is a
+// prompt to join.
+func eventReminderLead(event calendar.Event) (time.Duration, bool) {
+	lead := time.Duration(-1)
+	for _, r := range event.Reminders {
+		if r >= 0 && (lead < 0 || r < lead) {
+			lead = r
+		}
+	}
+	return lead, lead >= 0
 }
 
 func (s *Scheduler) notifyMultiple(events []calendar.Event) {
@@ -174,31 +570,123 @@ func (s *Scheduler) notifyMultiple(events []calendar.Event) {
 	meetings := make([]notifier.Meeting, len(events))
 	for i, event := range events {
 		meetings[i] = notifier.Meeting{
-			Title:    event.Title,
-			MeetLink: event.MeetLink,
+			Title:   event.Title,
+			JoinURL: event.JoinURL,
+			CanRSVP: event.ResponseStatus == "needsAction" && s.CanRSVP(&event),
 		}
 	}
 
-	result, err := notifier.ShowMeetingAlert(meetings)
+	result, err := s.notifier.ShowMeetingAlert(meetings)
 	if err != nil {
 		log.Printf("Failed to show alert: %v", err)
 		return
 	}
 
-	if result.Joined && result.Index >= 0 && result.Index < len(events) {
-		selectedEvent := events[result.Index]
-		log.Printf("Opening Meet: %s", selectedEvent.MeetLink)
-		if err := notifier.OpenMeetLink(selectedEvent.MeetLink); err != nil {
-			log.Printf("Failed to open Meet link: %v", err)
+	// The action applies to the selected meeting, or to all of them for index -1
+	selected := events
+	if result.Index >= 0 && result.Index < len(events) {
+		selected = events[result.Index : result.Index+1]
+	}
+
+	if result.Response != "" && len(selected) == 1 {
+		s.RSVP(&selected[0], result.Response)
+	}
+
+	switch result.Action {
+	case notifier.AlertJoin:
+		if len(selected) == 1 {
+			s.OpenMeeting(&selected[0])
 		}
-	} else {
+	case notifier.AlertSnooze:
+		until := s.now().Add(result.Snooze)
+		for _, event := range selected {
+			log.Printf("Snoozed %s until %s", event.Title, until.Format("15:04:05"))
+			s.snoozed[meetingKey(event)] = until
+		}
+	case notifier.AlertDismiss:
+		for _, event := range selected {
+			log.Printf("Dismissed %s", event.Title)
+			s.dismissed[meetingKey(event)] = true
+		}
+	default:
 		log.Printf("User cancelled or closed the dialog")
 	}
 }
 
-func (s *Scheduler) cleanupOldEvents() {
-	if len(s.notifiedEvents) > 100 {
-		s.notifiedEvents = make(map[eventKey]bool)
+// CanRSVP reports whether RSVP is enabled and you are a guest of the event.
+func (s *Scheduler) CanRSVP(event *calendar.Event) bool {
+	if !s.config.RSVP {
+		return false
+	}
+	if _, ok := s.source.(calendar.RSVPSource); !ok {
+		return false
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.Self && !attendee.Organizer {
+			return true
+		}
+	}
+	return false
+}
+
+// RSVP responds to the event's invitation and updates the cached event so the
+// change shows before the next fetch. Failures are shown as a notification.
+func (s *Scheduler) RSVP(event *calendar.Event, response string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := calendar.RSVP(ctx, s.source, *event, response); err != nil {
+		log.Printf("Failed to respond %s to %s: %v", response, event.Title, err)
+		if err := s.notifier.ShowNotification(event.Title, "Could not respond: "+err.Error()); err != nil {
+			log.Printf("Failed to show notification: %v", err)
+		}
+		return
+	}
+	log.Printf("Responded %s to %s", response, event.Title)
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	// Replace the slice rather than modifying it, readers may still hold the old one
+	events := make([]calendar.Event, 0, len(s.cachedEvents))
+	for _, cached := range s.cachedEvents {
+		if cached.ID == event.ID && cached.CalendarID == event.CalendarID && cached.Account == event.Account {
+			// Declined events are not shown, as when they are fetched
+			if response == calendar.ResponseDeclined {
+				continue
+			}
+			cached.ResponseStatus = response
+		}
+		events = append(events, cached)
+	}
+	s.cachedEvents = events
+}
+
+// OpenMeeting opens the join URL of the event, using the native app if configured for its provider.
+func (s *Scheduler) OpenMeeting(event *calendar.Event) {
+	nativeApp := slices.Contains(s.config.NativeApps, string(event.Provider))
+	s.joinMu.Lock()
+	s.joined[meetingKey(*event)] = true
+	s.joinMu.Unlock()
+
+	log.Printf("Opening %s: %s", event.Provider.DisplayName(), event.JoinURL)
+	if err := s.notifier.OpenMeetLink(event.JoinURL, nativeApp); err != nil {
+		log.Printf("Failed to open join link: %v", err)
+	}
+}
+
+func (s *Scheduler) hasJoined(event calendar.Event) bool {
+	s.joinMu.Lock()
+	defer s.joinMu.Unlock()
+	return s.joined[meetingKey(event)]
+}
+
+// cleanupOldEvents forgets reminders of meetings that started more than
+// missed_lookback ago; dueReminders does not announce them any more.
+func (s *Scheduler) cleanupOldEvents(now time.Time) {
+	for key := range s.notifiedEvents {
+		if key.startTime.Before(now.Add(-s.config.MissedLookback.Duration)) {
+			delete(s.notifiedEvents, key)
+		}
 	}
 }
 
@@ -207,7 +695,7 @@ func (s *Scheduler) GetOngoingEvent() *calendar.Event {
 	events := s.cachedEvents
 	s.cacheMu.RUnlock()
 
-	now := time.Now()
+	now := s.now()
 	for i := range events {
 		started := events[i].StartTime.Compare(now) <= 0
 		ended := events[i].EndTime.Compare(now) <= 0
@@ -223,7 +711,7 @@ func (s *Scheduler) GetNextEvent() *calendar.Event {
 	events := s.cachedEvents
 	s.cacheMu.RUnlock()
 
-	now := time.Now()
+	now := s.now()
 	for i := range events {
 		if events[i].StartTime.Compare(now) > 0 {
 			return &events[i]
@@ -232,8 +720,13 @@ func (s *Scheduler) GetNextEvent() *calendar.Event {
 	return nil
 }
 
+// Sync asks the Run loop to fetch now. Fetches only run on the Run loop so the
+// fetch and retry state need no locking; a request already pending is not repeated.
 func (s *Scheduler) Sync() {
-	s.fetchEvents(context.Background())
+	select {
+	case s.syncCh <- struct{}{}:
+	default:
+	}
 }
 
 func PIDFilePath() (string, error) {
@@ -273,17 +766,96 @@ func ReadPID() (int, error) {
 	return strconv.Atoi(string(data))
 }
 
+// NewEventSource builds the event source from the config: Google Calendar when
+// authenticated, plus any configured ICS, CalDAV and Microsoft 365 calendars.
+func NewEventSource(ctx context.Context, cfg *config.Config) (calendar.EventSource, error) {
+	var sources []calendar.EventSource
+
+	store, err := cfg.TokenStore.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token store: %w", err)
+	}
+
+	token, tokenErr := store.Load(calendar.TokenKey(""))
+	if tokenErr == nil {
+		client, err := calendar.NewClient(ctx, token, cfg.Calendars, calendar.ClientOptions{Store: store, Reminders: cfg.GoogleReminders})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create calendar client: %w", err)
+		}
+		sources = append(sources, client)
+	}
+
+	for _, account := range cfg.Accounts {
+		token, err := store.Load(calendar.TokenKey(account.Name))
+		if err != nil {
+			return nil, fmt.Errorf("not authenticated with account %s, run 'ooi auth --account %s' first: %w", account.Name, account.Name, err)
+		}
+		client, err := calendar.NewClient(ctx, token, account.Calendars, calendar.ClientOptions{
+			Account:   account.Name,
+			Email:     account.Email,
+			Store:     store,
+			Reminders: cfg.GoogleReminders,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create calendar client for account %s: %w", account.Name, err)
+		}
+		sources = append(sources, client)
+	}
+
+	for _, ics := range cfg.ICS {
+		sources = append(sources, calendar.NewICSSource(ics.Location(), calendar.ICSOptions{
+			Name:  ics.Name,
+			Email: ics.Email,
+		}))
+	}
+
+	for _, caldav := range cfg.CalDAV {
+		creds, err := calendar.LoadCalDAVCredentials(store, caldav.Name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, calendar.NewCalDAVSource(caldav.URL, creds, calendar.CalDAVOptions{
+			Name:  caldav.Name,
+			Email: caldav.Email,
+		}))
+	}
+
+	if cfg.Microsoft.Enabled() {
+		token, err := store.Load(calendar.GraphTokenKey)
+		if err != nil {
+			return nil, fmt.Errorf("not authenticated with Microsoft, run 'ooi auth microsoft' first: %w", err)
+		}
+		oauthConfig := calendar.GraphOAuthConfig(cfg.Microsoft.ClientID, cfg.Microsoft.Tenant)
+		httpClient := calendar.PersistentClient(ctx, oauthConfig, store, calendar.GraphTokenKey, token)
+		sources = append(sources, calendar.NewGraphSource(httpClient, calendar.GraphOptions{
+			BaseURL: cfg.Microsoft.BaseURL,
+			Name:    cfg.Microsoft.Name,
+		}))
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("not authenticated, run 'ooi auth' first: %w", tokenErr)
+	}
+
+	return calendar.MergeSources(sources...), nil
+}
+
 func Start(ctx context.Context) error {
-	token, err := calendar.LoadToken()
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	source, err := NewEventSource(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("not authenticated, run 'ooi auth' first: %w", err)
+		return err
 	}
 
-	client, err := calendar.NewClient(ctx, token)
+	rules, err := filter.Load()
 	if err != nil {
-		return fmt.Errorf("failed to create calendar client: %w", err)
+		return err
 	}
 
-	scheduler := NewScheduler(client)
+	scheduler := NewScheduler(source, cfg, rules)
 	return scheduler.Run(ctx)
 }
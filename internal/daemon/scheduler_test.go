@@ -1,6 +1,9 @@
 package daemon
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -8,6 +11,9 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+	"github.com/knwoop/ooi/internal/notifier"
 )
 
 func TestPIDFileWriteAndRead(t *testing.T) {
@@ -37,48 +43,33 @@ func TestPIDFileWriteAndRead(t *testing.T) {
 }
 
 func TestSchedulerNotifiedEventsCleanup(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	lookback := config.Default().MissedLookback.Duration
 	tests := []struct {
-		name       string
-		eventCount int
-		wantCount  int
+		name      string
+		startTime time.Time
+		wantKept  bool
 	}{
-		{
-			name:       "cleanup when over 100",
-			eventCount: 101,
-			wantCount:  0,
-		},
-		{
-			name:       "no cleanup when under 100",
-			eventCount: 50,
-			wantCount:  50,
-		},
-		{
-			name:       "no cleanup at exactly 100",
-			eventCount: 100,
-			wantCount:  100,
-		},
+		{name: "upcoming meeting", startTime: now.Add(time.Hour), wantKept: true},
+		{name: "meeting within missed_lookback", startTime: now.Add(-lookback + time.Minute), wantKept: true},
+		{name: "meeting at missed_lookback", startTime: now.Add(-lookback), wantKept: true},
+		{name: "meeting before missed_lookback", startTime: now.Add(-lookback - time.Minute), wantKept: false},
+		{name: "yesterday's meeting", startTime: now.Add(-24 * time.Hour), wantKept: false},
 	}
 
-	baseTime := time.Now()
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Scheduler{
+				config:         config.Default(),
 				notifiedEvents: make(map[eventKey]bool),
 			}
+			key := eventKey{eventID: "meeting", startTime: tt.startTime}
+			s.notifiedEvents[key] = true
 
-			for i := range tt.eventCount {
-				key := eventKey{
-					eventID:   strconv.Itoa(i),
-					startTime: baseTime.Add(time.Duration(i) * time.Minute),
-				}
-				s.notifiedEvents[key] = true
-			}
-
-			s.cleanupOldEvents()
+			s.cleanupOldEvents(now)
 
-			if diff := cmp.Diff(tt.wantCount, len(s.notifiedEvents)); diff != "" {
-				t.Errorf("notifiedEvents count mismatch (-want +got):\n%s", diff)
+			if diff := cmp.Diff(tt.wantKept, s.notifiedEvents[key]); diff != "" {
+				t.Errorf("kept mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
@@ -116,3 +107,539 @@ func TestEventKeyWithRescheduledMeeting(t *testing.T) {
 		t.Error("Rescheduled meeting should NOT be marked as notified")
 	}
 }
+
+func TestEventReminderLead(t *testing.T) {
+	tests := []struct {
+		name      string
+		reminders []time.Duration
+		want      time.Duration
+		wantOK    bool
+	}{
+		{
+			name:      "no reminders uses the schedule",
+			reminders: nil,
+			want:      -1,
+		},
+		{
+			name:      "closest reminder to start wins",
+			reminders: []time.Duration{15 * time.Minute, 5 * time.Minute},
+			want:      5 * time.Minute,
+			wantOK:    true,
+		},
+		{
+			name:      "reminders after start are ignored",
+			reminders: []time.Duration{-5 * time.Minute, 10 * time.Minute},
+			want:      10 * time.Minute,
+			wantOK:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := eventReminderLead(calendar.Event{Reminders: tt.reminders})
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("eventReminderLead mismatch (-want +got):\n%s", diff)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("eventReminderLead ok = %v, want %v", ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+type fakeSource struct {
+	events    []calendar.Event
+	lookback  time.Duration
+	lookahead time.Duration
+}
+
+func (f *fakeSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	f.lookback = lookback
+	f.lookahead = lookahead
+	return f.events, nil
+}
+
+func TestFetchEventsUsesRollingHorizon(t *testing.T) {
+	// A meeting early tomorrow must be visible even shortly before midnight
+	tomorrowMorning := time.Now().Add(9 * time.Hour)
+	source := &fakeSource{
+		events: []calendar.Event{{ID: "standup", Title: "Standup", StartTime: tomorrowMorning, EndTime: tomorrowMorning.Add(15 * time.Minute)}},
+	}
+
+	cfg := config.Default()
+	cfg.Lookahead = config.Duration{Duration: 48 * time.Hour}
+	s := NewScheduler(source, cfg, nil)
+	s.fetchEvents(context.Background())
+
+	if diff := cmp.Diff(48*time.Hour, source.lookahead); diff != "" {
+		t.Errorf("lookahead mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(config.Default().MissedLookback.Duration, source.lookback); diff != "" {
+		t.Errorf("lookback mismatch (-want +got):\n%s", diff)
+	}
+
+	next := s.GetNextEvent()
+	if next == nil || next.ID != "standup" {
+		t.Errorf("GetNextEvent = %v, want standup", next)
+	}
+}
+
+func TestDueEventsAcrossDSTTransition(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Clocks jump from 02:00 EST to 03:00 EDT on 2026-03-08
+	now := time.Date(2026, 3, 8, 1, 59, 30, 0, ny)
+	onTime := time.Date(2026, 3, 8, 3, 0, 0, 0, ny)
+	later := time.Date(2026, 3, 8, 3, 30, 0, 0, ny)
+
+	s := NewScheduler(&fakeSource{}, nil, nil)
+	s.cachedEvents = []calendar.Event{
+		{ID: "a", Title: "On time", StartTime: onTime, EndTime: onTime.Add(30 * time.Minute)},
+		{ID: "b", Title: "Later", StartTime: later, EndTime: later.Add(30 * time.Minute)},
+	}
+
+	var got []string
+	due, _ := s.dueReminders(now)
+	for _, d := range due {
+		got = append(got, d.event.ID)
+	}
+	if diff := cmp.Diff([]string{"a"}, got); diff != "" {
+		t.Errorf("due events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchEventsExcludesAllDay(t *testing.T) {
+	start := time.Now().Add(30 * time.Second)
+	source := &fakeSource{
+		events: []calendar.Event{
+			{ID: "holiday", Title: "Holiday", StartTime: start, EndTime: start.Add(24 * time.Hour), AllDay: true},
+			{ID: "standup", Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute)},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		alertAllDay bool
+		want        []string
+	}{
+		{
+			name: "all-day events are excluded by default",
+			want: []string{"standup"},
+		},
+		{
+			name:        "alert_all_day includes them",
+			alertAllDay: true,
+			want:        []string{"holiday", "standup"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.AlertAllDay = tt.alertAllDay
+			s := NewScheduler(source, cfg, nil)
+			s.fetchEvents(context.Background())
+
+			var got []string
+			due, _ := s.dueReminders(time.Now())
+			for _, d := range due {
+				got = append(got, d.event.ID)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("due events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeOutOfOfficeSource struct {
+	fakeSource
+	periods []calendar.Period
+}
+
+func (f *fakeOutOfOfficeSource) OutOfOffice(start, end time.Time) []calendar.Period {
+	return f.periods
+}
+
+func TestAlertAction(t *testing.T) {
+	vacationStart := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	source := &fakeOutOfOfficeSource{
+		periods: []calendar.Period{{Title: "Vacation", Start: vacationStart, End: vacationStart.AddDate(0, 0, 5)}},
+	}
+
+	cfg := config.Default()
+	cfg.DisplayTimeZone = "UTC"
+	cfg.QuietHours.Weekends = true
+	if err := cfg.QuietHours.Start.UnmarshalText([]byte("19:00")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+	if err := cfg.QuietHours.End.UnmarshalText([]byte("09:00")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+
+	s := NewScheduler(source, cfg, nil)
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	s.fetchEvents(context.Background())
+
+	tests := []struct {
+		name       string
+		t          time.Time
+		wantAction config.AlertAction
+		wantReason string
+	}{
+		{
+			name:       "working hours",
+			t:          time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+			wantAction: config.ActionAlert,
+		},
+		{
+			name:       "evening",
+			t:          time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC),
+			wantAction: config.ActionNotify,
+			wantReason: "quiet hours (19:00–09:00)",
+		},
+		{
+			name:       "weekend",
+			t:          time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC),
+			wantAction: config.ActionNotify,
+			wantReason: "quiet hours (weekend)",
+		},
+		{
+			name:       "out of office",
+			t:          time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+			wantAction: config.ActionSuppress,
+			wantReason: "out of office (Vacation)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, reason := s.alertAction(tt.t)
+			if diff := cmp.Diff(tt.wantAction, action); diff != "" {
+				t.Errorf("action mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReason, reason); diff != "" {
+				t.Errorf("reason mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if diff := cmp.Diff("", s.SuppressionReason()); diff != "" {
+		t.Errorf("suppression reason mismatch (-want +got):\n%s", diff)
+	}
+	s.now = func() time.Time { return time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC) }
+	if diff := cmp.Diff("Alerts off: out of office (Vacation)", s.SuppressionReason()); diff != "" {
+		t.Errorf("suppression reason mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(10*time.Second, time.Minute)
+	b.jitter = func() float64 { return 1 }
+
+	var got []time.Duration
+	for range 5 {
+		got = append(got, b.next(0))
+	}
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("delays mismatch (-want +got):\n%s", diff)
+	}
+
+	// Retry-After takes precedence when it is longer
+	if diff := cmp.Diff(5*time.Minute, b.next(5*time.Minute)); diff != "" {
+		t.Errorf("delay mismatch (-want +got):\n%s", diff)
+	}
+
+	b.reset()
+	b.jitter = func() float64 { return 0 }
+	if diff := cmp.Diff(5*time.Second, b.next(0)); diff != "" {
+		t.Errorf("delay after reset mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type errorSource struct {
+	err error
+}
+
+func (e *errorSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	return nil, e.err
+}
+
+func TestFetchEventsRetriesTemporaryErrors(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	source := &errorSource{err: &calendar.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}}
+
+	s := NewScheduler(source, nil, nil)
+	s.now = func() time.Time { return now }
+	s.backoff.jitter = func() float64 { return 1 }
+
+	s.fetchEvents(context.Background())
+	if diff := cmp.Diff(now.Add(retryBaseDelay), s.retryAt); diff != "" {
+		t.Errorf("retry time mismatch (-want +got):\n%s", diff)
+	}
+
+	s.fetchEvents(context.Background())
+	if diff := cmp.Diff(now.Add(2*retryBaseDelay), s.retryAt); diff != "" {
+		t.Errorf("retry time mismatch (-want +got):\n%s", diff)
+	}
+
+	// A successful fetch clears the retry
+	s.source = &fakeSource{}
+	s.fetchEvents(context.Background())
+	if !s.retryAt.IsZero() {
+		t.Errorf("retryAt = %v, want zero", s.retryAt)
+	}
+	if diff := cmp.Diff(0, s.backoff.attempt); diff != "" {
+		t.Errorf("backoff attempt mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type flakySource struct {
+	events []calendar.Event
+	err    error
+}
+
+func (f *flakySource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	return f.events, f.err
+}
+
+func TestFetchEventsKeepsFailedCalendars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events-cache.json")
+	fetchedAt := time.Now().Truncate(time.Second)
+	review := fetchedAt.Add(30 * time.Minute)
+	standup := fetchedAt.Add(2 * time.Hour)
+	google := &flakySource{events: []calendar.Event{
+		{ID: "standup", Title: "Standup", CalendarID: "primary", StartTime: standup, EndTime: standup.Add(15 * time.Minute), JoinURL: "https://meet.google.com/standup"},
+	}}
+	ics := &flakySource{events: []calendar.Event{
+		{ID: "review", Title: "Review", CalendarID: "team.ics", StartTime: review, EndTime: review.Add(30 * time.Minute), JoinURL: "https://meet.google.com/review"},
+	}}
+
+	fake := &fakeNotifier{}
+	now := fetchedAt
+	s := NewScheduler(calendar.MergeSources(google, ics), nil, nil)
+	s.notifier = fake
+	s.cachePath = path
+	s.now = func() time.Time { return now }
+	s.backoff.jitter = func() float64 { return 1 }
+	s.fetchEvents(context.Background())
+
+	// The ICS calendar fails later; its meeting is still alerted
+	ics.err = &calendar.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+	now = review.Add(-30 * time.Second)
+	s.fetchEvents(context.Background())
+	s.checkAlerts()
+
+	want := [][]notifier.Meeting{{{Title: "Review", JoinURL: "https://meet.google.com/review"}}}
+	if diff := cmp.Diff(want, fake.alerts); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+	var ids []string
+	for _, event := range s.cachedEvents {
+		ids = append(ids, event.ID)
+	}
+	if diff := cmp.Diff([]string{"review", "standup"}, ids); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(now.Add(retryBaseDelay), s.retryAt); diff != "" {
+		t.Errorf("retry time mismatch (-want +got):\n%s", diff)
+	}
+
+	// The partial fetch neither refreshes the fetch time nor the cache
+	if got, _ := s.Stale(); !got.Equal(fetchedAt) {
+		t.Errorf("fetchedAt = %v, want %v", got, fetchedAt)
+	}
+	cache, err := loadCacheFile(path, config.Default())
+	if err != nil {
+		t.Fatalf("loadCacheFile failed: %v", err)
+	}
+	if !cache.FetchedAt.Equal(fetchedAt) || len(cache.Events) != 2 {
+		t.Errorf("cache was rewritten: fetched at %v with %d events", cache.FetchedAt, len(cache.Events))
+	}
+}
+
+type blockingSource struct{}
+
+func (blockingSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFetchEventsTimesOut(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.FetchInterval = config.Duration{Duration: 10 * time.Millisecond}
+	s := NewScheduler(blockingSource{}, cfg, nil)
+	s.now = func() time.Time { return now }
+	s.backoff.jitter = func() float64 { return 1 }
+
+	// A server that never answers is retried like an offline one
+	s.fetchEvents(context.Background())
+	if diff := cmp.Diff(now.Add(retryBaseDelay), s.retryAt); diff != "" {
+		t.Errorf("retry time mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type countingSource struct {
+	errorSource
+	calls int
+}
+
+func (c *countingSource) GetEventsInRange(ctx context.Context, lookback, lookahead time.Duration) ([]calendar.Event, error) {
+	c.calls++
+	return c.errorSource.GetEventsInRange(ctx, lookback, lookahead)
+}
+
+func TestFetchHonorsRetryAfter(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	now := start
+	source := &countingSource{errorSource: errorSource{err: &calendar.HTTPError{
+		StatusCode: 429,
+		Status:     "429 Too Many Requests",
+		Header:     http.Header{"Retry-After": []string{"600"}},
+	}}}
+
+	cfg := config.Default()
+	cfg.FetchInterval = config.Duration{Duration: 3 * time.Minute}
+	s := NewScheduler(source, cfg, nil)
+	s.notifier = &fakeNotifier{}
+	s.now = func() time.Time { return now }
+	s.backoff.jitter = func() float64 { return 1 }
+	ctx := context.Background()
+
+	s.fetchEvents(ctx)
+	if diff := cmp.Diff(start.Add(10*time.Minute), s.retryAt); diff != "" {
+		t.Fatalf("retry time mismatch (-want +got):\n%s", diff)
+	}
+
+	// Regular fetches wait for the retry
+	for _, elapsed := range []time.Duration{3 * time.Minute, 6 * time.Minute, 9 * time.Minute} {
+		now = start.Add(elapsed)
+		s.onFetchTick(ctx)
+		s.onAlertTick(ctx)
+	}
+	if diff := cmp.Diff(1, source.calls); diff != "" {
+		t.Errorf("fetches before Retry-After mismatch (-want +got):\n%s", diff)
+	}
+
+	now = start.Add(10 * time.Minute)
+	s.onAlertTick(ctx)
+	if diff := cmp.Diff(2, source.calls); diff != "" {
+		t.Errorf("fetches after Retry-After mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without Retry-After the backoff grows beyond fetch_interval
+	source.err = &calendar.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+	for range 5 {
+		now = s.retryAt
+		s.onAlertTick(ctx)
+	}
+	if delay := s.retryAt.Sub(now); delay <= cfg.FetchInterval.Duration {
+		t.Errorf("retry delay %s should exceed fetch_interval %s", delay, cfg.FetchInterval.Duration)
+	}
+}
+
+type rsvpSource struct {
+	fakeSource
+	responses []string
+}
+
+func (f *rsvpSource) RSVP(ctx context.Context, event calendar.Event, response string) error {
+	f.responses = append(f.responses, event.ID+" "+response)
+	return nil
+}
+
+func TestSchedulerRSVP(t *testing.T) {
+	invited := []calendar.Attendee{{Person: calendar.Person{Self: true}, ResponseStatus: "needsAction"}}
+	source := &rsvpSource{}
+	cfg := config.Default()
+	cfg.RSVP = true
+	s := NewScheduler(source, cfg, nil)
+	s.cachedEvents = []calendar.Event{
+		{ID: "a", Title: "Review", ResponseStatus: "needsAction", Attendees: invited},
+		{ID: "b", Title: "Town hall", ResponseStatus: "needsAction", Attendees: invited},
+		{ID: "c", Title: "Own meeting", ResponseStatus: "accepted"},
+	}
+
+	if diff := cmp.Diff([]bool{true, true, false}, []bool{s.CanRSVP(&s.cachedEvents[0]), s.CanRSVP(&s.cachedEvents[1]), s.CanRSVP(&s.cachedEvents[2])}); diff != "" {
+		t.Errorf("CanRSVP mismatch (-want +got):\n%s", diff)
+	}
+
+	s.RSVP(&s.cachedEvents[0], calendar.ResponseAccepted)
+	s.RSVP(&s.cachedEvents[1], calendar.ResponseDeclined)
+
+	if diff := cmp.Diff([]string{"a accepted", "b declined"}, source.responses); diff != "" {
+		t.Errorf("responses mismatch (-want +got):\n%s", diff)
+	}
+	var got []string
+	for _, event := range s.cachedEvents {
+		got = append(got, event.ID+" "+event.ResponseStatus)
+	}
+	if diff := cmp.Diff([]string{"a accepted", "c accepted"}, got); diff != "" {
+		t.Errorf("cached events mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg.RSVP = false
+	if s.CanRSVP(&s.cachedEvents[0]) {
+		t.Error("expected CanRSVP to be false when rsvp is disabled")
+	}
+}
+
+func TestSyncDoesNotFetch(t *testing.T) {
+	source := &countingSource{}
+	s := NewScheduler(source, nil, nil)
+
+	// Sync only queues a request for Run and never blocks the menu bar
+	s.Sync()
+	s.Sync()
+	if diff := cmp.Diff(0, source.calls); diff != "" {
+		t.Errorf("fetch count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1, len(s.syncCh)); diff != "" {
+		t.Errorf("pending sync requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuthErrorMessage(t *testing.T) {
+	forbidden := &calendar.HTTPError{StatusCode: 403, Status: "403 Forbidden"}
+	unauthorized := &calendar.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "default account",
+			err:  unauthorized,
+			want: "Session expired. Please run 'ooi auth' to re-authenticate.",
+		},
+		{
+			name: "caldav",
+			err:  &calendar.SourceError{Source: "Fastmail", AuthCommand: "ooi auth caldav --name Fastmail", Err: unauthorized},
+			want: "Session for Fastmail expired. Please run 'ooi auth caldav --name Fastmail' to re-authenticate.",
+		},
+		{
+			name: "microsoft",
+			err:  &calendar.SourceError{Source: "Outlook", AuthCommand: "ooi auth microsoft", Err: forbidden},
+			want: "Calendar access for Outlook was revoked. Please run 'ooi auth microsoft' to authorize ooi again.",
+		},
+		{
+			name: "ics",
+			err:  &calendar.SourceError{Source: "Holidays", Err: fmt.Errorf("failed to fetch calendar: %w", forbidden)},
+			want: "Holidays returned 403 Forbidden.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := authErrorMessage(calendar.Classify(tt.err), tt.err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("message mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
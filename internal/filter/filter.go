@@ -0,0 +1,283 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/knwoop/ooi/internal/calendar"
+	"github.com/knwoop/ooi/internal/config"
+)
+
+type Action string
+
+const (
+	Include Action = "include"
+	Exclude Action = "exclude"
+)
+
+// Rules decide which events alert. Rules are evaluated in order and the first
+// matching rule wins; events matching no rule get the default action.
+// A nil *Rules includes every event.
+type Rules struct {
+	Default Action `toml:"default"`
+	Rules   []Rule `toml:"rule"`
+
+	// AutoJoin selects the meetings that are joined without a dialog.
+	// It has the same format, but its default action is exclude.
+	AutoJoin *Rules `toml:"auto_join"`
+}
+
+// Rule matches an event when all of its set conditions match.
+type Rule struct {
+	Name   string `toml:"name"`
+	Action Action `toml:"action"`
+
+	// Title and Organizer are regular expressions
+	Title     string `toml:"title"`
+	Organizer string `toml:"organizer"`
+	// Calendars match the calendar ID or name
+	Calendars    []string `toml:"calendars"`
+	Colors       []string `toml:"colors"`
+	EventTypes   []string `toml:"event_types"`
+	MinAttendees *int     `toml:"min_attendees"`
+	MaxAttendees *int     `toml:"max_attendees"`
+	// Optional matches whether you are an optional attendee
+	Optional *bool `toml:"optional"`
+	// Transparency is "opaque" (busy) or "transparent" (free)
+	Transparency string `toml:"transparency"`
+	// ResponseStatus matches your response: accepted, tentative or needsAction
+	ResponseStatus []string `toml:"response_status"`
+	// OrganizerSelf matches whether you are the organizer
+	OrganizerSelf *bool `toml:"organizer_self"`
+
+	// Reminders replaces the reminder schedule for matching events
+	Reminders []config.Reminder `toml:"reminder"`
+
+	title     *regexp.Regexp
+	organizer *regexp.Regexp
+}
+
+// Decision is the result of evaluating the rules for an event.
+type Decision struct {
+	Included bool
+	// Rule is the index of the matching rule, or -1 if the default action applied
+	Rule   int
+	Reason string
+	// Reminders is the schedule set by the matching rule, or nil for the default
+	Reminders []config.Reminder
+}
+
+func (d Decision) String() string {
+	verb := "included"
+	if !d.Included {
+		verb = "excluded"
+	}
+	return verb + " " + d.Reason
+}
+
+func Path() (string, error) {
+	configDir, err := calendar.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "rules.toml"), nil
+}
+
+// Load reads rules.toml. A missing file yields rules that include every event.
+func Load() (*Rules, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	rules, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse parses and validates rules in TOML.
+func Parse(data string) (*Rules, error) {
+	var rules Rules
+	md, err := toml.Decode(data, &rules)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %q", undecoded[0].String())
+	}
+
+	if err := rules.compile(Include); err != nil {
+		return nil, err
+	}
+
+	if rules.AutoJoin != nil {
+		if rules.AutoJoin.AutoJoin != nil {
+			return nil, fmt.Errorf("auto_join: auto_join cannot be nested")
+		}
+		if err := rules.AutoJoin.compile(Exclude); err != nil {
+			return nil, fmt.Errorf("auto_join: %w", err)
+		}
+	}
+	return &rules, nil
+}
+
+func (rs *Rules) compile(defaultAction Action) error {
+	if rs.Default == "" {
+		rs.Default = defaultAction
+	}
+	if err := validAction(rs.Default); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+
+	for i := range rs.Rules {
+		if err := rs.Rules[i].compile(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validAction(a Action) error {
+	if a != Include && a != Exclude {
+		return fmt.Errorf("action must be %q or %q, got %q", Include, Exclude, a)
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	if err := validAction(r.Action); err != nil {
+		return err
+	}
+
+	var err error
+	if r.Title != "" {
+		if r.title, err = regexp.Compile(r.Title); err != nil {
+			return fmt.Errorf("invalid title pattern: %w", err)
+		}
+	}
+	if r.Organizer != "" {
+		if r.organizer, err = regexp.Compile(r.Organizer); err != nil {
+			return fmt.Errorf("invalid organizer pattern: %w", err)
+		}
+	}
+
+	if r.Transparency != "" && r.Transparency != "opaque" && r.Transparency != "transparent" {
+		return fmt.Errorf("transparency must be \"opaque\" or \"transparent\", got %q", r.Transparency)
+	}
+	if err := config.ValidateReminders(r.Reminders); err != nil {
+		return err
+	}
+	return nil
+}
+
+// match returns the conditions that matched, or false if any condition did not match.
+func (r *Rule) match(event calendar.Event) ([]string, bool) {
+	var matched []string
+	check := func(ok bool, desc string) bool {
+		if ok {
+			matched = append(matched, desc)
+		}
+		return ok
+	}
+
+	if r.title != nil && !check(r.title.MatchString(event.Title), fmt.Sprintf("title matches %q", r.Title)) {
+		return nil, false
+	}
+	if r.organizer != nil && !check(r.organizer.MatchString(event.Organizer.Email), fmt.Sprintf("organizer matches %q", r.Organizer)) {
+		return nil, false
+	}
+	if len(r.Calendars) > 0 {
+		ok := slices.Contains(r.Calendars, event.CalendarID) || slices.Contains(r.Calendars, event.CalendarName)
+		if !check(ok, "calendar is "+event.CalendarID) {
+			return nil, false
+		}
+	}
+	if len(r.Colors) > 0 && !check(slices.Contains(r.Colors, event.ColorID), "color is "+event.ColorID) {
+		return nil, false
+	}
+	if len(r.EventTypes) > 0 && !check(slices.Contains(r.EventTypes, event.EventType), "event type is "+event.EventType) {
+		return nil, false
+	}
+	attendees := len(event.Attendees)
+	if r.MinAttendees != nil && !check(attendees >= *r.MinAttendees, fmt.Sprintf("%d attendees >= %d", attendees, *r.MinAttendees)) {
+		return nil, false
+	}
+	if r.MaxAttendees != nil && !check(attendees <= *r.MaxAttendees, fmt.Sprintf("%d attendees <= %d", attendees, *r.MaxAttendees)) {
+		return nil, false
+	}
+	if r.Optional != nil && !check(event.Optional == *r.Optional, fmt.Sprintf("optional is %t", event.Optional)) {
+		return nil, false
+	}
+	if r.Transparency != "" && !check(event.Transparency == r.Transparency, "transparency is "+event.Transparency) {
+		return nil, false
+	}
+	if len(r.ResponseStatus) > 0 && !check(slices.Contains(r.ResponseStatus, event.ResponseStatus), "response is "+event.ResponseStatus) {
+		return nil, false
+	}
+	if r.OrganizerSelf != nil && !check(event.Organizer.Self == *r.OrganizerSelf, fmt.Sprintf("organizer_self is %t", event.Organizer.Self)) {
+		return nil, false
+	}
+	return matched, true
+}
+
+// Evaluate decides whether the event alerts.
+func (rs *Rules) Evaluate(event calendar.Event) Decision {
+	if rs == nil {
+		return Decision{Included: true, Rule: -1, Reason: "(no rules)"}
+	}
+
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		matched, ok := r.match(event)
+		if !ok {
+			continue
+		}
+
+		label := fmt.Sprintf("by rule %d", i+1)
+		if r.Name != "" {
+			label += fmt.Sprintf(" %q", r.Name)
+		}
+		if len(matched) > 0 {
+			label += ": " + strings.Join(matched, ", ")
+		}
+		return Decision{Included: r.Action == Include, Rule: i, Reason: label, Reminders: r.Reminders}
+	}
+
+	return Decision{Included: rs.Default == Include, Rule: -1, Reason: "by default"}
+}
+
+// EvaluateAutoJoin decides whether the event is joined without a dialog.
+// Without auto_join rules no event is.
+func (rs *Rules) EvaluateAutoJoin(event calendar.Event) Decision {
+	if rs == nil || rs.AutoJoin == nil {
+		return Decision{Included: false, Rule: -1, Reason: "(no auto_join rules)"}
+	}
+	return rs.AutoJoin.Evaluate(event)
+}
+
+// Apply returns the events the rules include.
+func (rs *Rules) Apply(events []calendar.Event) []calendar.Event {
+	var result []calendar.Event
+	for _, event := range events {
+		if rs.Evaluate(event).Included {
+			result = append(result, event)
+		}
+	}
+	return result
+}
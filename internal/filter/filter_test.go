@@ -0,0 +1,225 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/ooi/internal/calendar"
+)
+
+const testRules = `
+default = "include"
+
+[[rule]]
+name = "focus time"
+action = "exclude"
+event_types = ["focusTime", "outOfOffice"]
+
+[[rule]]
+name = "important"
+action = "include"
+organizer = "@boss\\.example\\.com$"
+
+[[rule]]
+action = "exclude"
+optional = true
+
+[[rule]]
+name = "town halls"
+action = "exclude"
+title = "(?i)town hall"
+min_attendees = 50
+
+[[rule]]
+action = "exclude"
+calendars = ["Holidays"]
+
+[[rule]]
+action = "exclude"
+transparency = "transparent"
+max_attendees = 0
+`
+
+func TestEvaluate(t *testing.T) {
+	rules, err := Parse(testRules)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event calendar.Event
+		want  Decision
+	}{
+		{
+			name:  "no rule matches",
+			event: calendar.Event{Title: "Standup", EventType: "default", Attendees: make([]calendar.Attendee, 5), Transparency: "opaque"},
+			want:  Decision{Included: true, Rule: -1, Reason: "by default"},
+		},
+		{
+			name:  "event type",
+			event: calendar.Event{Title: "Deep work", EventType: "focusTime"},
+			want:  Decision{Included: false, Rule: 0, Reason: `by rule 1 "focus time": event type is focusTime`},
+		},
+		{
+			name:  "first matching rule wins",
+			event: calendar.Event{Title: "1:1", Organizer: calendar.Person{Email: "ceo@boss.example.com"}, Optional: true},
+			want:  Decision{Included: true, Rule: 1, Reason: `by rule 2 "important": organizer matches "@boss\\.example\\.com$"`},
+		},
+		{
+			name:  "optional attendee",
+			event: calendar.Event{Title: "Review", Optional: true},
+			want:  Decision{Included: false, Rule: 2, Reason: "by rule 3: optional is true"},
+		},
+		{
+			name:  "all conditions must match",
+			event: calendar.Event{Title: "Town Hall", Attendees: make([]calendar.Attendee, 10)},
+			want:  Decision{Included: true, Rule: -1, Reason: "by default"},
+		},
+		{
+			name:  "title and attendee count",
+			event: calendar.Event{Title: "Town Hall", Attendees: make([]calendar.Attendee, 120)},
+			want:  Decision{Included: false, Rule: 3, Reason: `by rule 4 "town halls": title matches "(?i)town hall", 120 attendees >= 50`},
+		},
+		{
+			name:  "calendar name",
+			event: calendar.Event{Title: "New Year", CalendarID: "en.japanese#holiday", CalendarName: "Holidays"},
+			want:  Decision{Included: false, Rule: 4, Reason: "by rule 5: calendar is en.japanese#holiday"},
+		},
+		{
+			name:  "free event without guests",
+			event: calendar.Event{Title: "Reminder", Transparency: "transparent"},
+			want:  Decision{Included: false, Rule: 5, Reason: "by rule 6: 0 attendees <= 0, transparency is transparent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Evaluate(tt.event)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Evaluate mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvaluateNilRules(t *testing.T) {
+	var rules *Rules
+	got := rules.Apply([]calendar.Event{{Title: "Standup"}})
+	if diff := cmp.Diff(1, len(got)); diff != "" {
+		t.Errorf("event count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDefaultExclude(t *testing.T) {
+	rules, err := Parse(`
+default = "exclude"
+
+[[rule]]
+action = "include"
+colors = ["11"]
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	events := []calendar.Event{
+		{Title: "Red", ColorID: "11"},
+		{Title: "Plain"},
+	}
+	var got []string
+	for _, e := range rules.Apply(events) {
+		got = append(got, e.Title)
+	}
+	if diff := cmp.Diff([]string{"Red"}, got); diff != "" {
+		t.Errorf("included events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid default", data: `default = "maybe"`},
+		{name: "missing action", data: "[[rule]]\ntitle = \"x\""},
+		{name: "invalid regexp", data: "[[rule]]\naction = \"exclude\"\ntitle = \"(\""},
+		{name: "invalid transparency", data: "[[rule]]\naction = \"exclude\"\ntransparency = \"busy\""},
+		{name: "invalid reminder kind", data: "[[rule]]\naction = \"include\"\n[[rule.reminder]]\nat = \"-5m\"\nkind = \"popup\""},
+		{name: "misspelled condition", data: "[[rule]]\naction = \"exclude\"\ntitel = \"lunch\""},
+		{name: "unknown auto_join key", data: "[auto_join]\ndefualt = \"include\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestEvaluateAutoJoin(t *testing.T) {
+	rules, err := Parse(`
+[[rule]]
+action = "exclude"
+title = "(?i)lunch"
+
+[auto_join]
+
+[[auto_join.rule]]
+name = "own meetings"
+action = "include"
+response_status = ["accepted"]
+organizer_self = true
+
+[[auto_join.rule]]
+name = "standup"
+action = "include"
+title = "(?i)standup"
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event calendar.Event
+		want  Decision
+	}{
+		{
+			name:  "accepted and organizer",
+			event: calendar.Event{Title: "1:1", ResponseStatus: "accepted", Organizer: calendar.Person{Self: true}},
+			want:  Decision{Included: true, Rule: 0, Reason: `by rule 1 "own meetings": response is accepted, organizer_self is true`},
+		},
+		{
+			name:  "title",
+			event: calendar.Event{Title: "Daily Standup", ResponseStatus: "needsAction"},
+			want:  Decision{Included: true, Rule: 1, Reason: `by rule 2 "standup": title matches "(?i)standup"`},
+		},
+		{
+			name:  "excluded by default",
+			event: calendar.Event{Title: "Review", ResponseStatus: "accepted"},
+			want:  Decision{Included: false, Rule: -1, Reason: "by default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.EvaluateAutoJoin(tt.event)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("EvaluateAutoJoin mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// The alert rules keep their own default
+	if got := rules.Evaluate(calendar.Event{Title: "Review"}); !got.Included {
+		t.Errorf("expected alert rules to include by default, got %v", got)
+	}
+
+	var none *Rules
+	if none.EvaluateAutoJoin(calendar.Event{Title: "Standup"}).Included {
+		t.Error("expected no auto-join without rules")
+	}
+}
@@ -0,0 +1,327 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Attendee struct {
+	Email    string
+	Name     string
+	PartStat string // ACCEPTED, DECLINED, TENTATIVE, NEEDS-ACTION
+	Role     string // REQ-PARTICIPANT, OPT-PARTICIPANT, NON-PARTICIPANT, CHAIR
+}
+
+// Attachment is an ATTACH property referring to a URI. Inline binary attachments are skipped.
+type Attachment struct {
+	URL      string
+	Filename string
+	FmtType  string
+}
+
+// Event is a single VEVENT, or one occurrence of a recurring VEVENT after expansion.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string // TENTATIVE, CONFIRMED, CANCELLED
+	Transp      string // OPAQUE, TRANSPARENT
+	Color       string // RFC 7986 COLOR
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Organizer   string
+	// OrganizerName is the organizer's CN
+	OrganizerName string
+	Attendees     []Attendee
+	Attachments   []Attachment
+	// Alarms holds VALARM lead times before the start (positive = before)
+	Alarms []time.Duration
+
+	recurrenceID time.Time
+	rrule        *RRule
+	rdates       []time.Time
+	exdates      []time.Time
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// ParseCalendar parses iCalendar data into events.
+// Floating times and dates are interpreted in the calendar's X-WR-TIMEZONE if set, otherwise in loc.
+func ParseCalendar(r io.Reader, loc *time.Location) (*Calendar, error) {
+	root, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("expected VCALENDAR, got %s", root.Name)
+	}
+
+	if tzid := root.Text("X-WR-TIMEZONE"); tzid != "" {
+		if calLoc, err := time.LoadLocation(tzid); err == nil {
+			loc = calLoc
+		}
+	}
+
+	z := newZones(root, loc)
+	cal := &Calendar{Name: root.Text("X-WR-CALNAME")}
+	for _, comp := range root.Components {
+		if comp.Name != "VEVENT" {
+			continue
+		}
+		event, err := parseEvent(comp, z)
+		if err != nil {
+			// Skip malformed events rather than failing the whole calendar
+			continue
+		}
+		cal.Events = append(cal.Events, event)
+	}
+	return cal, nil
+}
+
+func parseEvent(comp *Component, z *zones) (Event, error) {
+	event := Event{
+		UID:         comp.Text("UID"),
+		Summary:     comp.Text("SUMMARY"),
+		Description: comp.Text("DESCRIPTION"),
+		Location:    comp.Text("LOCATION"),
+		URL:         comp.Text("URL"),
+		Status:      strings.ToUpper(comp.Text("STATUS")),
+		Transp:      strings.ToUpper(comp.Text("TRANSP")),
+		Color:       comp.Text("COLOR"),
+		Organizer:   mailto(comp.Prop("ORGANIZER")),
+	}
+	if org := comp.Prop("ORGANIZER"); org != nil {
+		event.OrganizerName = org.Params["CN"]
+	}
+
+	dtstart := comp.Prop("DTSTART")
+	if dtstart == nil {
+		return Event{}, fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := z.parseDateTime(dtstart.Value, dtstart.Params)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+	event.Start = start
+	event.AllDay = allDay
+
+	switch {
+	case comp.Prop("DTEND") != nil:
+		dtend := comp.Prop("DTEND")
+		end, _, err := z.parseDateTime(dtend.Value, dtend.Params)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid DTEND: %w", err)
+		}
+		event.End = end
+	case comp.Prop("DURATION") != nil:
+		d, err := parseDuration(comp.Text("DURATION"))
+		if err != nil {
+			return Event{}, err
+		}
+		event.End = start.Add(d)
+	case allDay:
+		event.End = start.AddDate(0, 0, 1)
+	default:
+		event.End = start
+	}
+
+	if rid := comp.Prop("RECURRENCE-ID"); rid != nil {
+		event.recurrenceID, _, err = z.parseDateTime(rid.Value, rid.Params)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid RECURRENCE-ID: %w", err)
+		}
+	}
+
+	if rrule := comp.Prop("RRULE"); rrule != nil {
+		event.rrule, err = parseRRule(rrule.Value, z)
+		if err != nil {
+			return Event{}, err
+		}
+	}
+	for _, p := range comp.Props("RDATE") {
+		event.rdates = append(event.rdates, z.parseDateTimes(p.Value, p.Params)...)
+	}
+	for _, p := range comp.Props("EXDATE") {
+		event.exdates = append(event.exdates, z.parseDateTimes(p.Value, p.Params)...)
+	}
+
+	for _, p := range comp.Props("ATTENDEE") {
+		event.Attendees = append(event.Attendees, Attendee{
+			Email:    mailto(&p),
+			Name:     p.Params["CN"],
+			PartStat: strings.ToUpper(p.Params["PARTSTAT"]),
+			Role:     strings.ToUpper(p.Params["ROLE"]),
+		})
+	}
+
+	for _, p := range comp.Props("ATTACH") {
+		if strings.EqualFold(p.Params["VALUE"], "BINARY") {
+			continue
+		}
+		filename := p.Params["FILENAME"]
+		if filename == "" {
+			filename = p.Params["X-FILENAME"]
+		}
+		event.Attachments = append(event.Attachments, Attachment{
+			URL:      p.Value,
+			Filename: filename,
+			FmtType:  p.Params["FMTTYPE"],
+		})
+	}
+
+	for _, sub := range comp.Components {
+		if sub.Name != "VALARM" {
+			continue
+		}
+		if lead, ok := alarmLead(sub, event, z); ok {
+			event.Alarms = append(event.Alarms, lead)
+		}
+	}
+
+	return event, nil
+}
+
+// alarmLead converts a VALARM TRIGGER into a lead time before the event start.
+func alarmLead(alarm *Component, event Event, z *zones) (time.Duration, bool) {
+	if strings.EqualFold(alarm.Text("ACTION"), "EMAIL") {
+		return 0, false
+	}
+	trigger := alarm.Prop("TRIGGER")
+	if trigger == nil {
+		return 0, false
+	}
+
+	if trigger.Params["VALUE"] == "DATE-TIME" {
+		t, _, err := z.parseDateTime(trigger.Value, nil)
+		if err != nil {
+			return 0, false
+		}
+		return event.Start.Sub(t), true
+	}
+
+	d, err := parseDuration(trigger.Value)
+	if err != nil {
+		return 0, false
+	}
+	if trigger.Params["RELATED"] == "END" {
+		return -(d + event.End.Sub(event.Start)), true
+	}
+	return -d, true
+}
+
+func mailto(p *Property) string {
+	if p == nil {
+		return ""
+	}
+	v := p.Value
+	if len(v) >= 7 && strings.EqualFold(v[:7], "mailto:") {
+		v = v[7:]
+	}
+	return v
+}
+
+// Expand returns all event occurrences overlapping [start, end), sorted by start time.
+// Recurring events are expanded using RRULE/RDATE minus EXDATE, and overridden
+// occurrences (RECURRENCE-ID) replace the generated ones. Cancelled occurrences are dropped.
+func (c *Calendar) Expand(start, end time.Time) []Event {
+	type occurrenceKey struct {
+		uid   string
+		start int64
+	}
+
+	overrides := make(map[occurrenceKey]Event)
+	for _, e := range c.Events {
+		if !e.recurrenceID.IsZero() {
+			overrides[occurrenceKey{e.UID, e.recurrenceID.Unix()}] = e
+		}
+	}
+
+	var result []Event
+	add := func(e Event) {
+		if e.Status == "CANCELLED" {
+			return
+		}
+		// Zero-length events overlap if they start inside the window
+		if e.Start.Before(end) && (e.End.After(start) || !e.Start.Before(start)) {
+			result = append(result, e)
+		}
+	}
+
+	for _, e := range c.Events {
+		if !e.recurrenceID.IsZero() {
+			continue
+		}
+		if e.rrule == nil && len(e.rdates) == 0 {
+			add(e)
+			continue
+		}
+
+		duration := e.End.Sub(e.Start)
+		starts := []time.Time{e.Start}
+		if e.rrule != nil {
+			starts = e.rrule.occurrences(e.Start, end)
+		}
+		starts = append(starts, e.rdates...)
+
+		seen := make(map[int64]bool)
+		for _, s := range starts {
+			if seen[s.Unix()] || containsTime(e.exdates, s) {
+				continue
+			}
+			seen[s.Unix()] = true
+
+			key := occurrenceKey{e.UID, s.Unix()}
+			if override, ok := overrides[key]; ok {
+				add(override)
+				delete(overrides, key)
+				continue
+			}
+
+			occurrence := e
+			occurrence.Start = s
+			occurrence.End = endOf(e, s, duration)
+			occurrence.rrule = nil
+			occurrence.rdates = nil
+			occurrence.exdates = nil
+			add(occurrence)
+		}
+	}
+
+	// Overrides whose original occurrence is outside the window may have been moved into it
+	for _, override := range overrides {
+		add(override)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// endOf computes an occurrence's end. All-day events keep their length in days
+// so that occurrences on DST transition days still end at midnight.
+func endOf(e Event, start time.Time, duration time.Duration) time.Time {
+	if e.AllDay {
+		days := int(e.End.Sub(e.Start).Hours()+12) / 24
+		return start.AddDate(0, 0, days)
+	}
+	return start.Add(duration)
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, x := range times {
+		if x.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
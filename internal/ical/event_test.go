@@ -0,0 +1,196 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+X-WR-CALNAME:Work
+BEGIN:VTIMEZONE
+TZID:Tokyo Standard Time
+BEGIN:STANDARD
+DTSTART:16010101T000000
+TZOFFSETFROM:+0900
+TZOFFSETTO:+0900
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:standup
+SUMMARY:Daily Standup
+DTSTART;TZID=Tokyo Standard Time:20260105T100000
+DTEND;TZID=Tokyo Standard Time:20260105T101500
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR
+EXDATE;TZID=Tokyo Standard Time:20260107T100000
+LOCATION:https://zoom.us/j/123
+BEGIN:VALARM
+ACTION:DISPLAY
+TRIGGER:-PT5M
+END:VALARM
+BEGIN:VALARM
+ACTION:EMAIL
+TRIGGER:-PT1H
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Tokyo Standard Time:20260109T100000
+SUMMARY:Daily Standup (moved)
+DTSTART;TZID=Tokyo Standard Time:20260109T140000
+DTEND;TZID=Tokyo Standard Time:20260109T141500
+END:VEVENT
+BEGIN:VEVENT
+UID:review
+SUMMARY:Design review\, part 1
+DESCRIPTION:Join here:\nhttps://meet.google.com/abc-defg-hij
+DTSTART:20260106T030000Z
+DURATION:PT1H
+END:VEVENT
+BEGIN:VEVENT
+UID:cancelled
+SUMMARY:Cancelled
+STATUS:CANCELLED
+DTSTART:20260106T050000Z
+DTEND:20260106T060000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseCalendarAndExpand(t *testing.T) {
+	cal, err := ParseCalendar(strings.NewReader(testCalendar), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseCalendar failed: %v", err)
+	}
+
+	if diff := cmp.Diff("Work", cal.Name); diff != "" {
+		t.Errorf("calendar name mismatch (-want +got):\n%s", diff)
+	}
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	events := cal.Expand(start, end)
+
+	type occurrence struct {
+		Summary string
+		Start   string
+	}
+	var got []occurrence
+	for _, e := range events {
+		got = append(got, occurrence{e.Summary, e.Start.UTC().Format(time.RFC3339)})
+	}
+
+	want := []occurrence{
+		{"Daily Standup", "2026-01-05T01:00:00Z"},
+		{"Design review, part 1", "2026-01-06T03:00:00Z"},
+		// Wednesday is excluded by EXDATE, Friday is moved by RECURRENCE-ID
+		{"Daily Standup (moved)", "2026-01-09T05:00:00Z"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("occurrences mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]time.Duration{5 * time.Minute}, events[0].Alarms); diff != "" {
+		t.Errorf("alarms mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("Join here:\nhttps://meet.google.com/abc-defg-hij", events[1].Description); diff != "" {
+		t.Errorf("description mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		end     time.Time
+		want    []string
+	}{
+		{
+			name:    "daily with count",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			end:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2026-01-01T09:00:00Z", "2026-01-02T09:00:00Z", "2026-01-03T09:00:00Z"},
+		},
+		{
+			name:    "every other week until",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;UNTIL=20260201T000000Z",
+			dtstart: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			end:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2026-01-01T09:00:00Z", "2026-01-15T09:00:00Z", "2026-01-29T09:00:00Z"},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			dtstart: time.Date(2026, 1, 30, 16, 0, 0, 0, time.UTC),
+			end:     time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2026-01-30T16:00:00Z", "2026-02-27T16:00:00Z", "2026-03-27T16:00:00Z"},
+		},
+		{
+			name:    "monthly on the 31st skips short months",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC),
+			end:     time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2026-01-31T09:00:00Z", "2026-03-31T09:00:00Z", "2026-05-31T09:00:00Z"},
+		},
+		{
+			name:    "wall clock time is kept across DST",
+			rule:    "FREQ=DAILY;COUNT=2",
+			dtstart: time.Date(2026, 3, 7, 9, 0, 0, 0, newYork),
+			end:     time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRRule(tt.rule, &zones{defaultLoc: time.UTC})
+			if err != nil {
+				t.Fatalf("parseRRule failed: %v", err)
+			}
+
+			var got []string
+			for _, occ := range rule.occurrences(tt.dtstart, tt.end) {
+				got = append(got, occ.Format(time.RFC3339))
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("occurrences mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"-PT15M", -15 * time.Minute},
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"-P1W", -7 * 24 * time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if err != nil {
+				t.Fatalf("parseDuration failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("duration mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package ical
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds recurrence expansion for rules without COUNT or UNTIL
+const maxPeriods = 100000
+
+type weekdayNum struct {
+	n       int // 0 = every occurrence, 1 = first, -1 = last
+	weekday time.Weekday
+}
+
+// RRule is the supported subset of an RFC 5545 recurrence rule:
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []weekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func parseRRule(value string, z *zones) (*RRule, error) {
+	r := &RRule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, isDate, err := z.parseDateTime(val, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", val)
+			}
+			if isDate {
+				// A date UNTIL includes the whole day
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.Until = t
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(m)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid BYMONTH %q", m)
+				}
+				r.ByMonth = append(r.ByMonth, n)
+			}
+		case "WKST":
+			wd, ok := weekdays[strings.ToUpper(val)]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST %q", val)
+			}
+			r.WeekStart = wd
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.Freq)
+	}
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (weekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	wd, ok := weekdays[s[len(s)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil {
+			return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+		}
+	}
+	return weekdayNum{n: n, weekday: wd}, nil
+}
+
+// occurrences returns the start times generated by the rule from dtstart up to (but excluding) end.
+// dtstart is always the first occurrence, as required by RFC 5545.
+func (r *RRule) occurrences(dtstart, end time.Time) []time.Time {
+	var result []time.Time
+	emitted := 0
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.candidates(dtstart, period)
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return result
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return result
+			}
+			if !t.Before(end) {
+				return result
+			}
+			result = append(result, t)
+			emitted++
+		}
+	}
+	return result
+}
+
+// candidates returns the sorted occurrence candidates in the given period after dtstart.
+// Dates are built with time.Date in dtstart's location so that the wall clock time
+// stays the same across DST transitions.
+func (r *RRule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	hour, minute, sec := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, 0, loc)
+	}
+
+	var days []time.Time
+	step := period * r.Interval
+	switch r.Freq {
+	case "DAILY":
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+step)
+		days = []time.Time{day}
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*step)
+		if len(r.ByDay) == 0 {
+			days = []time.Time{at(weekStart.Year(), weekStart.Month(), weekStart.Day()+offset)}
+			break
+		}
+		for i := range 7 {
+			day := at(weekStart.Year(), weekStart.Month(), weekStart.Day()+i)
+			if r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case "MONTHLY":
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(step), 1)
+		days = r.daysInMonth(first.Year(), first.Month(), dtstart.Day(), at)
+	case "YEARLY":
+		year := dtstart.Year() + step
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(dtstart.Month())}
+		}
+		for _, m := range months {
+			days = append(days, r.daysInMonth(year, time.Month(m), dtstart.Day(), at)...)
+		}
+	}
+
+	var result []time.Time
+	for _, day := range days {
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(day.Month())) {
+			continue
+		}
+		if r.Freq == "DAILY" && len(r.ByDay) > 0 && !r.matchesWeekday(day) {
+			continue
+		}
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+func (r *RRule) daysInMonth(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = lastDay + d + 1
+			}
+			if d >= 1 && d <= lastDay {
+				days = append(days, at(year, month, d))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			var matches []int
+			for d := 1; d <= lastDay; d++ {
+				if time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.weekday {
+					matches = append(matches, d)
+				}
+			}
+			switch {
+			case wd.n == 0:
+				for _, d := range matches {
+					days = append(days, at(year, month, d))
+				}
+			case wd.n > 0 && wd.n <= len(matches):
+				days = append(days, at(year, month, matches[wd.n-1]))
+			case wd.n < 0 && -wd.n <= len(matches):
+				days = append(days, at(year, month, matches[len(matches)+wd.n]))
+			}
+		}
+	default:
+		// Months without the start day (e.g. the 31st) are skipped
+		if defaultDay <= lastDay {
+			days = append(days, at(year, month, defaultDay))
+		}
+	}
+	return days
+}
+
+func (r *RRule) matchesWeekday(t time.Time) bool {
+	for _, wd := range r.ByDay {
+		if wd.weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
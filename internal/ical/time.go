@@ -0,0 +1,182 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// windowsZones maps common Windows time zone names used by Outlook/Exchange to IANA names.
+var windowsZones = map[string]string{
+	"UTC":                            "UTC",
+	"GMT Standard Time":              "Europe/London",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Romance Standard Time":          "Europe/Paris",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"E. Europe Standard Time":        "Europe/Chisinau",
+	"FLE Standard Time":              "Europe/Kiev",
+	"Russian Standard Time":          "Europe/Moscow",
+	"India Standard Time":            "Asia/Kolkata",
+	"China Standard Time":            "Asia/Shanghai",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"Korea Standard Time":            "Asia/Seoul",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"New Zealand Standard Time":      "Pacific/Auckland",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"US Mountain Standard Time":      "America/Phoenix",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"Alaskan Standard Time":          "America/Anchorage",
+	"Hawaiian Standard Time":         "Pacific/Honolulu",
+	"E. South America Standard Time": "America/Sao_Paulo",
+}
+
+// zones resolves TZID parameters to locations.
+type zones struct {
+	// fallback holds fixed offsets from VTIMEZONE definitions for unknown TZIDs
+	fallback map[string]*time.Location
+	// defaultLoc is used for floating times and dates
+	defaultLoc *time.Location
+}
+
+func newZones(cal *Component, defaultLoc *time.Location) *zones {
+	z := &zones{fallback: make(map[string]*time.Location), defaultLoc: defaultLoc}
+	for _, comp := range cal.Components {
+		if comp.Name != "VTIMEZONE" {
+			continue
+		}
+		tzid := comp.Text("TZID")
+		for _, sub := range comp.Components {
+			if sub.Name != "STANDARD" {
+				continue
+			}
+			offset, err := parseUTCOffset(sub.Text("TZOFFSETTO"))
+			if err == nil {
+				z.fallback[tzid] = time.FixedZone(tzid, offset)
+			}
+			break
+		}
+	}
+	return z
+}
+
+func (z *zones) location(tzid string) *time.Location {
+	if tzid == "" {
+		return z.defaultLoc
+	}
+	tzid = strings.TrimPrefix(tzid, "/")
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	if loc, ok := z.fallback[tzid]; ok {
+		return loc
+	}
+	return z.defaultLoc
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. The bool result reports whether the value is a date.
+func (z *zones) parseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, z.defaultLoc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, z.location(params["TZID"]))
+	return t, false, err
+}
+
+// parseDateTimes parses a comma-separated list such as EXDATE values.
+func (z *zones) parseDateTimes(value string, params map[string]string) []time.Time {
+	var times []time.Time
+	for _, v := range strings.Split(value, ",") {
+		if t, _, err := z.parseDateTime(strings.TrimSpace(v), params); err == nil {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// parseDuration parses RFC 5545 durations like "-PT15M", "P1D" or "P1W".
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r == 'T':
+			inTime = true
+		case r >= '0' && r <= '9':
+			num += string(r)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			num = ""
+			switch {
+			case r == 'W' && !inTime:
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				d += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return sign * d, nil
+}
+
+// parseUTCOffset parses offsets like "+0900" or "-0430".
+func parseUTCOffset(s string) (int, error) {
+	if len(s) < 5 {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	hours, err := strconv.Atoi(s[1:3])
+	if err != nil {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	minutes, err := strconv.Atoi(s[3:5])
+	if err != nil {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
@@ -7,37 +7,74 @@ import (
 
 	"fyne.io/systray"
 	"github.com/knwoop/ooi/internal/calendar"
-	"github.com/knwoop/ooi/internal/notifier"
 )
 
 type EventProvider interface {
 	GetOngoingEvent() *calendar.Event
 	GetNextEvent() *calendar.Event
 	Sync()
+	OpenMeeting(event *calendar.Event)
+	// SuppressionReason explains why alerts are muted, or is empty
+	SuppressionReason() string
+	// Stale returns when events were last fetched and whether they are out of date
+	Stale() (time.Time, bool)
+	// CanRSVP reports whether you can respond to the event's invitation from ooi
+	CanRSVP(event *calendar.Event) bool
+	RSVP(event *calendar.Event, response string)
+	// PendingAutoJoin returns the meeting about to be joined automatically, or nil
+	PendingAutoJoin() *calendar.Event
+	CancelAutoJoin()
 }
 
-func Run(ctx context.Context, provider EventProvider) {
-	systray.Run(func() { onReady(ctx, provider) }, onExit)
+// Options controls how the menu bar shows meetings.
+type Options struct {
+	// Location is the time zone start times are displayed in
+	Location *time.Location
+	// TitleLength is how many characters of the meeting title are shown in the menu bar
+	TitleLength int
 }
 
-func onReady(ctx context.Context, provider EventProvider) {
+// Run shows the menu bar item.
+func Run(ctx context.Context, provider EventProvider, opts Options) {
+	systray.Run(func() { onReady(ctx, provider, opts) }, onExit)
+}
+
+func onReady(ctx context.Context, provider EventProvider, opts Options) {
 	systray.SetTitle("📅 No meetings")
 	systray.SetTooltip("ooi - Meeting Reminder")
 
 	mMeetingInfo := systray.AddMenuItem("No meetings", "Current meeting info")
 	mMeetingInfo.Disable()
 
+	mSuppressed := systray.AddMenuItem("", "Why alerts are muted")
+	mSuppressed.Disable()
+	mSuppressed.Hide()
+
+	mStale := systray.AddMenuItem("", "Calendar could not be synced")
+	mStale.Disable()
+	mStale.Hide()
+
 	systray.AddSeparator()
 
-	mOpenMeet := systray.AddMenuItem("Open Meet", "Open Google Meet link")
+	mOpenMeet := systray.AddMenuItem("Join", "Open meeting link")
 	mOpenMeet.Disable()
 
+	mCancelJoin := systray.AddMenuItem("Cancel auto-join", "Do not join the meeting automatically")
+	mCancelJoin.Hide()
+
+	mRSVP := systray.AddMenuItem("Respond to next meeting", "RSVP to the next meeting")
+	mAccept := mRSVP.AddSubMenuItemCheckbox("Accept", "Accept the invitation", false)
+	mTentative := mRSVP.AddSubMenuItemCheckbox("Maybe", "Accept tentatively", false)
+	mDecline := mRSVP.AddSubMenuItemCheckbox("Decline", "Decline the invitation", false)
+	mRSVP.Hide()
+
 	systray.AddSeparator()
 
 	mSync := systray.AddMenuItem("Sync", "Sync calendar")
 	mQuit := systray.AddMenuItem("Quit", "Quit ooi")
 
-	var currentMeetLink string
+	var currentEvent *calendar.Event
+	var rsvpEvent *calendar.Event
 
 	// Start update ticker
 	ticker := time.NewTicker(1 * time.Second)
@@ -46,7 +83,39 @@ func onReady(ctx context.Context, provider EventProvider) {
 	update := func() {
 		ongoing := provider.GetOngoingEvent()
 		next := provider.GetNextEvent()
-		updateDisplay(ongoing, next, mMeetingInfo, mOpenMeet, &currentMeetLink)
+		reason := provider.SuppressionReason()
+		fetchedAt, stale := provider.Stale()
+
+		var prefix string
+		if stale {
+			prefix += "⚠️ "
+		}
+		if reason != "" {
+			prefix += "🔕 "
+		}
+		updateDisplay(ongoing, next, opts, prefix, mMeetingInfo, mOpenMeet, &currentEvent)
+		updateSuppressed(reason, mSuppressed)
+		updateStale(fetchedAt, stale, opts.Location, mStale)
+
+		updateAutoJoin(provider.PendingAutoJoin(), mCancelJoin)
+
+		rsvpEvent = nil
+		if next != nil && provider.CanRSVP(next) {
+			rsvpEvent = next
+		}
+		updateRSVP(rsvpEvent, mRSVP, map[string]*systray.MenuItem{
+			calendar.ResponseAccepted:  mAccept,
+			calendar.ResponseTentative: mTentative,
+			calendar.ResponseDeclined:  mDecline,
+		})
+	}
+
+	respond := func(response string) {
+		if rsvpEvent == nil {
+			return
+		}
+		event := *rsvpEvent
+		go provider.RSVP(&event, response)
 	}
 
 	go func() {
@@ -66,9 +135,18 @@ func onReady(ctx context.Context, provider EventProvider) {
 			case <-mSync.ClickedCh:
 				provider.Sync()
 			case <-mOpenMeet.ClickedCh:
-				if currentMeetLink != "" {
-					notifier.OpenMeetLink(currentMeetLink)
+				if currentEvent != nil {
+					provider.OpenMeeting(currentEvent)
 				}
+			case <-mCancelJoin.ClickedCh:
+				provider.CancelAutoJoin()
+				mCancelJoin.Hide()
+			case <-mAccept.ClickedCh:
+				respond(calendar.ResponseAccepted)
+			case <-mTentative.ClickedCh:
+				respond(calendar.ResponseTentative)
+			case <-mDecline.ClickedCh:
+				respond(calendar.ResponseDeclined)
 			case <-mQuit.ClickedCh:
 				systray.Quit()
 				return
@@ -81,18 +159,24 @@ func onExit() {
 	// Cleanup if needed
 }
 
-func updateDisplay(ongoing, next *calendar.Event, mInfo, mOpenMeet *systray.MenuItem, meetLink *string) {
+// updateDisplay shows the ongoing or next event. prefix marks muted alerts or stale data in the title.
+func updateDisplay(ongoing, next *calendar.Event, opts Options, prefix string, mInfo, mOpenMeet *systray.MenuItem, current **calendar.Event) {
+	setTitle := func(title string) {
+		systray.SetTitle(prefix + title)
+	}
+
 	if ongoing != nil {
 		remaining := time.Until(ongoing.EndTime)
 		mins := int(remaining.Minutes())
 		if mins < 0 {
 			mins = 0
 		}
-		title := truncateTitle(ongoing.Title, 20)
-		systray.SetTitle(fmt.Sprintf("🟢 %dm %s", mins, title))
-		mInfo.SetTitle(fmt.Sprintf("Ongoing: %s (%dm remaining)", ongoing.Title, mins))
+		title := truncateTitle(ongoing.Title, opts.TitleLength)
+		setTitle(fmt.Sprintf("🟢 %dm %s", mins, title))
+		mInfo.SetTitle(fmt.Sprintf("Ongoing: %s (%dm remaining)%s", ongoing.Title, mins, calendarSuffix(ongoing)))
 		mInfo.Enable()
-		*meetLink = ongoing.MeetLink
+		*current = ongoing
+		mOpenMeet.SetTitle("Join " + ongoing.Provider.DisplayName())
 		mOpenMeet.Enable()
 		return
 	}
@@ -103,22 +187,82 @@ func updateDisplay(ongoing, next *calendar.Event, mInfo, mOpenMeet *systray.Menu
 		if mins < 0 {
 			mins = 0
 		}
-		title := truncateTitle(next.Title, 20)
-		systray.SetTitle(fmt.Sprintf("⏳ %dm %s", mins, title))
-		mInfo.SetTitle(fmt.Sprintf("Next: %s (in %dm)", next.Title, mins))
+		title := truncateTitle(next.Title, opts.TitleLength)
+		setTitle(fmt.Sprintf("⏳ %dm %s", mins, title))
+		mInfo.SetTitle(fmt.Sprintf("Next: %s (at %s, in %dm)%s", next.Title, next.StartTime.In(opts.Location).Format("15:04"), mins, calendarSuffix(next)))
 		mInfo.Enable()
-		*meetLink = next.MeetLink
+		*current = next
+		mOpenMeet.SetTitle("Join " + next.Provider.DisplayName())
 		mOpenMeet.Enable()
 		return
 	}
 
-	systray.SetTitle("📅 No meetings")
+	setTitle("📅 No meetings")
 	mInfo.SetTitle("No meetings")
 	mInfo.Disable()
-	*meetLink = ""
+	*current = nil
+	mOpenMeet.SetTitle("Join")
 	mOpenMeet.Disable()
 }
 
+func updateAutoJoin(pending *calendar.Event, mCancelJoin *systray.MenuItem) {
+	if pending == nil {
+		mCancelJoin.Hide()
+		return
+	}
+	mCancelJoin.SetTitle("Cancel auto-join: " + truncateTitle(pending.Title, 30))
+	mCancelJoin.Show()
+}
+
+// updateRSVP shows the response items for event, checking your current response. A nil event hides them.
+func updateRSVP(event *calendar.Event, mRSVP *systray.MenuItem, items map[string]*systray.MenuItem) {
+	if event == nil {
+		mRSVP.Hide()
+		return
+	}
+	mRSVP.SetTitle("Respond to " + truncateTitle(event.Title, 30))
+	for response, item := range items {
+		if event.ResponseStatus == response {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+	mRSVP.Show()
+}
+
+func updateSuppressed(reason string, mSuppressed *systray.MenuItem) {
+	if reason == "" {
+		mSuppressed.Hide()
+		return
+	}
+	mSuppressed.SetTitle("🔕 " + reason)
+	mSuppressed.Show()
+}
+
+func updateStale(fetchedAt time.Time, stale bool, loc *time.Location, mStale *systray.MenuItem) {
+	if !stale {
+		mStale.Hide()
+		return
+	}
+	mStale.SetTitle("⚠️ Offline: events from " + fetchedAt.In(loc).Format("01/02 15:04"))
+	mStale.Show()
+}
+
+func calendarSuffix(event *calendar.Event) string {
+	name := event.CalendarName
+	if name == "" {
+		name = event.CalendarID
+	}
+	if name == "" {
+		return ""
+	}
+	if event.Account != "" {
+		name += " (" + event.Account + ")"
+	}
+	return " · " + name
+}
+
 func truncateTitle(title string, maxLen int) string {
 	runes := []rune(title)
 	if len(runes) <= maxLen {
@@ -2,105 +2,253 @@ package notifier
 
 import (
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/knwoop/ooi/internal/calendar"
+)
+
+// AlertAction is what was chosen in the meeting alert.
+type AlertAction int
+
+const (
+	// AlertClosed means the dialog was cancelled with Cmd-. or Esc
+	AlertClosed AlertAction = iota
+	// AlertJoin joins the meeting
+	AlertJoin
+	// AlertSnooze shows the alert again after AlertResult.Snooze
+	AlertSnooze
+	// AlertDismiss stops reminding of the meeting
+	AlertDismiss
 )
 
 type AlertResult struct {
-	Joined bool
-	Index  int // Index of selected meeting (-1 if cancelled)
+	Action AlertAction
+	// Index of the selected meeting, or -1 for all meetings in the alert
+	Index int
+	// Snooze is how long to wait before alerting again, for AlertSnooze
+	Snooze time.Duration
+	// Response is the RSVP chosen in the dialog (accepted or declined), or empty
+	Response string
 }
 
 // Meeting represents a meeting for the alert dialog
 type Meeting struct {
-	Title    string
-	MeetLink string
+	Title   string
+	JoinURL string
+	// CanRSVP offers Accept and Decline buttons for an unanswered invitation
+	CanRSVP bool
 }
 
 func ShowMeetingAlert(meetings []Meeting) (AlertResult, error) {
 	if len(meetings) == 0 {
-		return AlertResult{Joined: false, Index: -1}, nil
+		return AlertResult{Action: AlertClosed, Index: -1}, nil
 	}
 
 	if len(meetings) == 1 {
-		return showSingleMeetingAlert(meetings[0].Title)
+		return showSingleMeetingAlert(meetings[0])
 	}
 
 	return showMultipleMeetingsAlert(meetings)
 }
 
-func showSingleMeetingAlert(title string) (AlertResult, error) {
-	script := fmt.Sprintf(`
-display dialog "Meeting starting!\n%s" with title "ooi" buttons {"Join"} default button "Join" with icon caution
-`, escapeAppleScript(title))
+const (
+	buttonJoin          = "Join"
+	buttonAcceptAndJoin = "Accept & Join"
+	buttonLater         = "Later…"
 
-	cmd := exec.Command("osascript", "-e", script)
-	_, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() == 1 {
-				return AlertResult{Joined: false, Index: -1}, nil
-			}
-		}
-		return AlertResult{Joined: false, Index: -1}, fmt.Errorf("failed to show alert: %w", err)
+	choiceSnooze1m = "Snooze 1 minute"
+	choiceSnooze5m = "Snooze 5 minutes"
+	choiceDismiss  = "Dismiss"
+	choiceDecline  = "Decline"
+
+	// Script output is prefixed so that a meeting titled like a choice is
+	// not mistaken for it.
+	prefixButton = "button:"
+	prefixLater  = "later:"
+)
+
+// laterScript wraps a dialog so that the Later… button asks for a snooze or
+// dismissal in a list, since a dialog has at most three buttons. It returns the
+// pressed button with prefixButton or the choice with prefixLater.
+func laterScript(dialog string, choices []string) string {
+	quoted := make([]string, len(choices))
+	for i, choice := range choices {
+		quoted[i] = fmt.Sprintf("\"%s\"", choice)
 	}
+	return fmt.Sprintf(`
+set answer to button returned of (%s)
+if answer is "%s" then
+	set choice to choose from list {%s} with title "ooi" with prompt "Remind me:" default items {"%s"}
+	if choice is false then return "%[5]s"
+	return "%[5]s" & item 1 of choice
+end if
+return "%[6]s" & answer
+`, dialog, buttonLater, strings.Join(quoted, ", "), choiceSnooze1m, prefixLater, prefixButton)
+}
 
-	return AlertResult{Joined: true, Index: 0}, nil
+// laterResult converts the output of laterScript for the meeting at index if
+// it is a choice from the Later… list.
+func laterResult(output string, index int) (AlertResult, bool) {
+	choice, ok := strings.CutPrefix(output, prefixLater)
+	if !ok {
+		return AlertResult{}, false
+	}
+	switch choice {
+	case choiceSnooze1m:
+		return AlertResult{Action: AlertSnooze, Index: index, Snooze: time.Minute}, true
+	case choiceSnooze5m:
+		return AlertResult{Action: AlertSnooze, Index: index, Snooze: 5 * time.Minute}, true
+	case choiceDismiss:
+		return AlertResult{Action: AlertDismiss, Index: index}, true
+	case choiceDecline:
+		return AlertResult{Action: AlertDismiss, Index: index, Response: calendar.ResponseDeclined}, true
+	}
+	return AlertResult{Action: AlertClosed, Index: -1}, true
+}
+
+func showSingleMeetingAlert(meeting Meeting) (AlertResult, error) {
+	buttons := fmt.Sprintf(`{"%s", "%s"}`, buttonLater, buttonJoin)
+	choices := []string{choiceSnooze1m, choiceSnooze5m, choiceDismiss}
+	if meeting.CanRSVP {
+		buttons = fmt.Sprintf(`{"%s", "%s", "%s"}`, buttonLater, buttonAcceptAndJoin, buttonJoin)
+		choices = append(choices, choiceDecline)
+	}
+
+	dialog := fmt.Sprintf(`display dialog "Meeting starting!\n%s" with title "ooi" buttons %s default button "%s" with icon caution`,
+		escapeAppleScript(meeting.Title), buttons, buttonJoin)
+
+	output, closed, err := runAlertScript(laterScript(dialog, choices))
+	if err != nil || closed {
+		return AlertResult{Action: AlertClosed, Index: -1}, err
+	}
+
+	return singleMeetingResult(output), nil
+}
+
+func singleMeetingResult(output string) AlertResult {
+	if result, ok := laterResult(output, 0); ok {
+		return result
+	}
+	switch strings.TrimPrefix(output, prefixButton) {
+	case buttonAcceptAndJoin:
+		return AlertResult{Action: AlertJoin, Index: 0, Response: calendar.ResponseAccepted}
+	default:
+		return AlertResult{Action: AlertJoin, Index: 0}
+	}
 }
 
 func showMultipleMeetingsAlert(meetings []Meeting) (AlertResult, error) {
 	const maxTitleLen = 20
 
-	// AppleScript buttons are limited to 3, use first 3 meetings
-	maxButtons := 3
+	// AppleScript buttons are limited to 3: the first 2 meetings and Later…
+	maxButtons := 2
 	if len(meetings) < maxButtons {
 		maxButtons = len(meetings)
 	}
 
 	// Build button list with truncated titles (AppleScript shows buttons right-to-left, so reverse order)
 	truncatedTitles := make([]string, maxButtons)
-	var buttons []string
+	buttons := []string{fmt.Sprintf("\"%s\"", buttonLater)}
 	for i := maxButtons - 1; i >= 0; i-- {
 		truncatedTitles[i] = truncate(meetings[i].Title, maxTitleLen)
 		buttons = append(buttons, fmt.Sprintf("\"%s\"", escapeAppleScript(truncatedTitles[i])))
 	}
 
-	script := fmt.Sprintf(`
-display dialog "Meeting starting!" with title "ooi" buttons {%s} default button 1 with icon caution
-set selectedButton to button returned of result
-return selectedButton
-`, strings.Join(buttons, ", "))
+	dialog := fmt.Sprintf(`display dialog "Meeting starting!" with title "ooi" buttons {%s} default button %d with icon caution`,
+		strings.Join(buttons, ", "), len(buttons))
 
+	selected, closed, err := runAlertScript(laterScript(dialog, []string{choiceSnooze1m, choiceSnooze5m, choiceDismiss}))
+	if err != nil || closed {
+		return AlertResult{Action: AlertClosed, Index: -1}, err
+	}
+
+	// Snoozing or dismissing applies to all meetings in the alert
+	if result, ok := laterResult(selected, -1); ok {
+		return result, nil
+	}
+
+	// Find the index of the selected meeting by truncated title
+	selected = strings.TrimPrefix(selected, prefixButton)
+	for i := 0; i < maxButtons; i++ {
+		if truncatedTitles[i] == selected {
+			return AlertResult{Action: AlertJoin, Index: i}, nil
+		}
+	}
+
+	return AlertResult{Action: AlertJoin, Index: 0}, nil
+}
+
+// runAlertScript runs an alert script and returns its output, or closed if the
+// dialog was cancelled.
+func runAlertScript(script string) (string, bool, error) {
 	cmd := exec.Command("osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() == 1 {
-				return AlertResult{Joined: false, Index: -1}, nil
+				return "", true, nil
 			}
 		}
-		return AlertResult{Joined: false, Index: -1}, fmt.Errorf("failed to show alert: %w", err)
+		return "", false, fmt.Errorf("failed to show alert: %w", err)
 	}
+	return strings.TrimSpace(string(output)), false, nil
+}
 
-	selected := strings.TrimSpace(string(output))
-
-	// Find the index of the selected meeting by truncated title
-	for i := 0; i < maxButtons; i++ {
-		if truncatedTitles[i] == selected {
-			return AlertResult{Joined: true, Index: i}, nil
+// OpenMeetLink opens a join URL in the browser.
+// If nativeApp is true, Zoom and Teams links are converted to deep links so the desktop app opens directly.
+func OpenMeetLink(link string, nativeApp bool) error {
+	if nativeApp {
+		if deepLink, ok := nativeLink(link); ok {
+			link = deepLink
 		}
 	}
+	cmd := exec.Command("open", link)
+	return cmd.Run()
+}
+
+func nativeLink(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
 
-	return AlertResult{Joined: true, Index: 0}, nil
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "zoom.us" || strings.HasSuffix(host, ".zoom.us"):
+		// https://example.zoom.us/j/123?pwd=abc -> zoommtg://zoom.us/join?action=join&confno=123&pwd=abc
+		confno, ok := strings.CutPrefix(u.Path, "/j/")
+		if !ok || confno == "" {
+			return "", false
+		}
+		q := url.Values{}
+		q.Set("action", "join")
+		q.Set("confno", confno)
+		if pwd := u.Query().Get("pwd"); pwd != "" {
+			q.Set("pwd", pwd)
+		}
+		return "zoommtg://zoom.us/join?" + q.Encode(), true
+	case host == "teams.microsoft.com" || host == "teams.live.com":
+		// https://teams.microsoft.com/l/meetup-join/... -> msteams://teams.microsoft.com/l/meetup-join/...
+		u.Scheme = "msteams"
+		return u.String(), true
+	}
+	return "", false
 }
 
-func OpenMeetLink(url string) error {
-	cmd := exec.Command("open", url)
-	return cmd.Run()
+// ShowNotification shows a non-blocking notification banner.
+func ShowNotification(title, message string) error {
+	script := fmt.Sprintf(`display notification "%s" with title "ooi" subtitle "%s"`, escapeAppleScript(message), escapeAppleScript(title))
+	cmd := exec.Command("osascript", "-e", script)
+	_, err := cmd.Output()
+	return err
 }
 
-func ShowAuthErrorAlert() error {
-	script := `display dialog "Session expired. Please run 'ooi auth' to re-authenticate." with title "ooi" buttons {"OK"} default button "OK" with icon stop`
+// ShowAuthErrorAlert tells the user to authenticate again.
+func ShowAuthErrorAlert(message string) error {
+	script := fmt.Sprintf(`display dialog "%s" with title "ooi" buttons {"OK"} default button "OK" with icon stop`, escapeAppleScript(message))
 	cmd := exec.Command("osascript", "-e", script)
 	_, err := cmd.Output()
 	return err